@@ -0,0 +1,174 @@
+package accounting
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Exporter writes accounting records as CSV. Both FYB markets share the
+// same columns; amounts in fiat are labelled with Currency.
+type Exporter struct {
+	Currency string // fiat currency, "SGD" or "SEK"
+	Exchange string // exchange name for tax formats, e.g. "FYB-SG"
+}
+
+// NewExporter returns an Exporter for the market served at apiBaseURL,
+// e.g. fyb.APIBaseURLForSGD.
+func NewExporter(apiBaseURL string) *Exporter {
+	currency := apiBaseURL[strings.LastIndex(apiBaseURL, "/")+1:]
+	exchange := "FYB-SG"
+	if currency == "SEK" {
+		exchange = "FYB-SE"
+	}
+	return &Exporter{Currency: currency, Exchange: exchange}
+}
+
+func (x *Exporter) currency(c string) string {
+	if c == Fiat {
+		return x.Currency
+	}
+	return c
+}
+
+func side(s Side) string {
+	switch s {
+	case Buy:
+		return "buy"
+	case Sell:
+		return "sell"
+	}
+	return string(s)
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// WriteExecutions writes one row per executed order.
+func (x *Exporter) WriteExecutions(w io.Writer, execs []Execution) error {
+	rows := [][]string{}
+	for _, e := range execs {
+		rows = append(rows, []string{
+			strconv.FormatInt(e.Ticket, 10), side(e.Side), e.Price.String(), e.Qty.String(),
+			e.Qty.Mul(e.Price).String(), e.Fee.String(), x.Currency,
+			timestamp(e.Created), timestamp(e.Executed),
+		})
+	}
+	return writeCSV(w, []string{"ticket", "side", "price", "qty", "value", "fee", "currency", "created", "executed"}, rows)
+}
+
+// WriteTrades writes one row per public trade.
+func (x *Exporter) WriteTrades(w io.Writer, trades fyb.Trades) error {
+	rows := [][]string{}
+	for _, t := range trades {
+		rows = append(rows, []string{
+			strconv.FormatInt(t.TID, 10), timestamp(time.Unix(t.Date, 0)),
+			t.Price.String(), t.Amount.String(), x.Currency,
+		})
+	}
+	return writeCSV(w, []string{"tid", "time", "price", "amount", "currency"}, rows)
+}
+
+// WriteWithdrawals writes one row per withdrawal.
+func (x *Exporter) WriteWithdrawals(w io.Writer, withdrawals []Withdrawal) error {
+	rows := [][]string{}
+	for _, wd := range withdrawals {
+		rows = append(rows, []string{
+			wd.ID, timestamp(wd.Time), x.currency(wd.Currency), wd.Amount.String(), wd.Fee.String(),
+		})
+	}
+	return writeCSV(w, []string{"id", "time", "currency", "amount", "fee"}, rows)
+}
+
+// WriteDisposals writes one row per disposed lot with its cost basis and
+// proceeds, as needed for capital gains reporting.
+func (x *Exporter) WriteDisposals(w io.Writer, disposals []Disposal) error {
+	rows := [][]string{}
+	for _, d := range disposals {
+		rows = append(rows, []string{
+			strconv.FormatInt(d.Ticket, 10), timestamp(d.Time),
+			strconv.FormatInt(d.Lot.Ticket, 10), timestamp(d.Lot.Acquired),
+			d.Lot.Qty.String(), d.Proceeds.String(), d.Lot.Cost.String(), d.Gain.String(), x.Currency,
+		})
+	}
+	return writeCSV(w, []string{
+		"sell_ticket", "sold", "buy_ticket", "acquired", "qty", "proceeds", "cost_basis", "gain", "currency",
+	}, rows)
+}
+
+// WriteKoinly writes executions and withdrawals in Koinly's universal CSV
+// format.
+func (x *Exporter) WriteKoinly(w io.Writer, execs []Execution, withdrawals []Withdrawal) error {
+	date := func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 UTC") }
+	amount := func(d decimal.Decimal) string {
+		if d.IsZero() {
+			return ""
+		}
+		return d.String()
+	}
+	rows := [][]string{}
+	for _, e := range execs {
+		value := e.Qty.Mul(e.Price)
+		fee, feeCurrency := amount(e.Fee), ""
+		if fee != "" {
+			feeCurrency = x.Currency
+		}
+		row := []string{date(e.Executed), value.String(), x.Currency, e.Qty.String(), BTC,
+			fee, feeCurrency, "", "", "", "FYB ticket " + strconv.FormatInt(e.Ticket, 10), ""}
+		if e.Side == Sell {
+			row[1], row[2], row[3], row[4] = e.Qty.String(), BTC, value.String(), x.Currency
+		}
+		rows = append(rows, row)
+	}
+	for _, wd := range withdrawals {
+		currency := x.currency(wd.Currency)
+		fee, feeCurrency := amount(wd.Fee), ""
+		if fee != "" {
+			feeCurrency = currency
+		}
+		rows = append(rows, []string{date(wd.Time), wd.Amount.String(), currency, "", "",
+			fee, feeCurrency, "", "", "", "FYB withdrawal " + wd.ID, ""})
+	}
+	return writeCSV(w, []string{
+		"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash",
+	}, rows)
+}
+
+// WriteCoinTracking writes executions and withdrawals in CoinTracking's
+// CSV import format.
+func (x *Exporter) WriteCoinTracking(w io.Writer, execs []Execution, withdrawals []Withdrawal) error {
+	date := func(t time.Time) string { return t.UTC().Format("02.01.2006 15:04:05") }
+	rows := [][]string{}
+	for _, e := range execs {
+		value := e.Qty.Mul(e.Price)
+		row := []string{"Trade", e.Qty.String(), BTC, value.String(), x.Currency,
+			e.Fee.String(), x.Currency, x.Exchange, "", "FYB ticket " + strconv.FormatInt(e.Ticket, 10), date(e.Executed)}
+		if e.Side == Sell {
+			row[1], row[2], row[3], row[4] = value.String(), x.Currency, e.Qty.String(), BTC
+		}
+		rows = append(rows, row)
+	}
+	for _, wd := range withdrawals {
+		currency := x.currency(wd.Currency)
+		rows = append(rows, []string{"Withdrawal", "", "", wd.Amount.String(), currency,
+			wd.Fee.String(), currency, x.Exchange, "", "FYB withdrawal " + wd.ID, date(wd.Time)})
+	}
+	return writeCSV(w, []string{
+		"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency",
+		"Fee", "Fee Currency", "Exchange", "Trade-Group", "Comment", "Date",
+	}, rows)
+}
@@ -0,0 +1,56 @@
+package accounting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	x := NewExporter(fyb.APIBaseURLForSEK)
+	require.Equal(t, "SEK", x.Currency)
+	require.Equal(t, "FYB-SE", x.Exchange)
+
+	buy := exec(1, Buy, "100", "1", 100)
+	buy.Created = time.Unix(50, 0)
+	sell := exec(2, Sell, "300", "0.5", 300)
+	sell.Created = time.Unix(250, 0)
+	sell.Fee = d("1.5")
+	withdrawals := []Withdrawal{{ID: "11750", Time: time.Unix(400, 0), Currency: BTC, Amount: d("0.5")}}
+
+	var buf bytes.Buffer
+	require.NoError(t, x.WriteExecutions(&buf, []Execution{buy, sell}))
+	require.Equal(t, strings.Join([]string{
+		"ticket,side,price,qty,value,fee,currency,created,executed",
+		"1,buy,100,1,100,0,SEK,1970-01-01T00:00:50Z,1970-01-01T00:01:40Z",
+		"2,sell,300,0.5,150,1.5,SEK,1970-01-01T00:04:10Z,1970-01-01T00:05:00Z",
+	}, "\n")+"\n", buf.String())
+
+	l := NewLedger(FIFO)
+	require.NoError(t, l.Ingest([]Execution{buy, sell}, nil))
+	buf.Reset()
+	require.NoError(t, x.WriteDisposals(&buf, l.Disposals()))
+	require.Equal(t, strings.Join([]string{
+		"sell_ticket,sold,buy_ticket,acquired,qty,proceeds,cost_basis,gain,currency",
+		"2,1970-01-01T00:05:00Z,1,1970-01-01T00:01:40Z,0.5,148.5,50,98.5,SEK",
+	}, "\n")+"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, x.WriteKoinly(&buf, []Execution{sell}, withdrawals))
+	require.Equal(t, strings.Join([]string{
+		"Date,Sent Amount,Sent Currency,Received Amount,Received Currency,Fee Amount,Fee Currency,Net Worth Amount,Net Worth Currency,Label,Description,TxHash",
+		"1970-01-01 00:05:00 UTC,0.5,BTC,150,SEK,1.5,SEK,,,,FYB ticket 2,",
+		"1970-01-01 00:06:40 UTC,0.5,BTC,,,,,,,,FYB withdrawal 11750,",
+	}, "\n")+"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, x.WriteCoinTracking(&buf, []Execution{buy}, nil))
+	require.Equal(t, strings.Join([]string{
+		"Type,Buy Amount,Buy Currency,Sell Amount,Sell Currency,Fee,Fee Currency,Exchange,Trade-Group,Comment,Date",
+		"Trade,1,BTC,100,SEK,0,SEK,FYB-SE,,FYB ticket 1,01.01.1970 00:01:40",
+	}, "\n")+"\n", buf.String())
+}
@@ -0,0 +1,293 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Method is a cost basis method.
+type Method int
+
+// Cost basis methods.
+const (
+	FIFO        Method = iota // first in, first out
+	LIFO                      // last in, first out
+	AverageCost               // all BTC held has the same unit cost
+)
+
+func (m Method) String() string {
+	switch m {
+	case FIFO:
+		return "fifo"
+	case LIFO:
+		return "lifo"
+	case AverageCost:
+		return "average"
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// Currency of a withdrawal. Fiat is SGD or SEK depending on the market.
+const (
+	BTC  = "BTC"
+	Fiat = "FIAT"
+)
+
+// Withdrawal is a withdrawal from the exchange account. FYB has no API to
+// list withdrawals, so they are recorded by the caller, e.g. from the
+// WithdrawResponse of each fyb.Withdraw call.
+type Withdrawal struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Currency string          `json:"currency"` // BTC or Fiat
+	Amount   decimal.Decimal `json:"amount"`
+	Fee      decimal.Decimal `json:"fee"` // in Currency, withdrawn on top of Amount
+}
+
+// Lot is BTC acquired by one buy. Cost includes the buy fee.
+type Lot struct {
+	Ticket   int64           `json:"ticket"` // 0 for the pooled lot of AverageCost
+	Acquired time.Time       `json:"acquired"`
+	Qty      decimal.Decimal `json:"qty"`
+	Cost     decimal.Decimal `json:"cost"`
+}
+
+// split takes qty off the lot and returns it as a separate lot with its
+// share of the cost.
+func (l *Lot) split(qty decimal.Decimal) Lot {
+	part := Lot{Ticket: l.Ticket, Acquired: l.Acquired, Qty: qty, Cost: l.Cost}
+	if !qty.Equal(l.Qty) {
+		part.Cost = l.Cost.Mul(qty).Div(l.Qty)
+	}
+	l.Qty = l.Qty.Sub(qty)
+	l.Cost = l.Cost.Sub(part.Cost)
+	return part
+}
+
+// Disposal is the sale of all or part of a lot.
+type Disposal struct {
+	Ticket   int64           `json:"ticket"` // the sell order
+	Time     time.Time       `json:"time"`
+	Lot      Lot             `json:"lot"`      // the part of the lot sold
+	Proceeds decimal.Decimal `json:"proceeds"` // net of the sell fee
+	Gain     decimal.Decimal `json:"gain"`
+}
+
+// Summary is the state of a Ledger marked to a price.
+type Summary struct {
+	Method     string          `json:"method"`
+	BTC        decimal.Decimal `json:"btc"`
+	Fiat       decimal.Decimal `json:"fiat"`
+	CostBasis  decimal.Decimal `json:"cost_basis"`
+	Mark       decimal.Decimal `json:"mark"`
+	Value      decimal.Decimal `json:"value"` // BTC at Mark
+	Realized   decimal.Decimal `json:"realized"`
+	Unrealized decimal.Decimal `json:"unrealized"`
+	Fees       decimal.Decimal `json:"fees"`
+}
+
+// ErrInsufficientBTC is returned when a sell or withdrawal exceeds the BTC
+// the ledger holds.
+var ErrInsufficientBTC = errors.New("not enough BTC in the ledger")
+
+// Ledger tracks BTC and fiat positions and realized P&L from executions and
+// withdrawals. It starts empty, so fiat goes negative as BTC is bought;
+// record deposits made before trading as negative fiat withdrawals.
+// Events must be added in time order; Ingest sorts them.
+type Ledger struct {
+	method    Method
+	btc       decimal.Decimal
+	fiat      decimal.Decimal
+	lots      []Lot
+	realized  decimal.Decimal
+	fees      decimal.Decimal
+	disposals []Disposal
+}
+
+// NewLedger returns an empty Ledger using method for cost basis.
+func NewLedger(method Method) *Ledger {
+	return &Ledger{method: method}
+}
+
+// Ingest adds executions and withdrawals in time order. Executions are
+// ordered by execution time; a withdrawal at the same second comes after
+// them.
+func (l *Ledger) Ingest(execs []Execution, withdrawals []Withdrawal) error {
+	execs = append([]Execution{}, execs...)
+	sort.SliceStable(execs, func(i, j int) bool { return execs[i].Executed.Before(execs[j].Executed) })
+	withdrawals = append([]Withdrawal{}, withdrawals...)
+	sort.SliceStable(withdrawals, func(i, j int) bool { return withdrawals[i].Time.Before(withdrawals[j].Time) })
+
+	for len(execs) > 0 || len(withdrawals) > 0 {
+		if len(withdrawals) == 0 || len(execs) > 0 && !execs[0].Executed.After(withdrawals[0].Time) {
+			if err := l.AddExecution(execs[0]); err != nil {
+				return err
+			}
+			execs = execs[1:]
+			continue
+		}
+		if err := l.AddWithdrawal(withdrawals[0]); err != nil {
+			return err
+		}
+		withdrawals = withdrawals[1:]
+	}
+	return nil
+}
+
+// AddExecution records a buy or sell.
+func (l *Ledger) AddExecution(e Execution) error {
+	value := e.Qty.Mul(e.Price)
+	switch e.Side {
+	case Buy:
+		l.fiat = l.fiat.Sub(value).Sub(e.Fee)
+		l.btc = l.btc.Add(e.Qty)
+		l.fees = l.fees.Add(e.Fee)
+		l.addLot(Lot{Ticket: e.Ticket, Acquired: e.Executed, Qty: e.Qty, Cost: value.Add(e.Fee)})
+	case Sell:
+		parts, err := l.take(e.Qty)
+		if err != nil {
+			return fmt.Errorf("ticket %d: %v", e.Ticket, err)
+		}
+		l.fiat = l.fiat.Add(value).Sub(e.Fee)
+		l.btc = l.btc.Sub(e.Qty)
+		l.fees = l.fees.Add(e.Fee)
+		proceeds := value.Sub(e.Fee)
+		remaining := proceeds
+		for i, lot := range parts {
+			// The last part gets what is left so the parts add up exactly.
+			share := remaining
+			if i < len(parts)-1 {
+				share = proceeds.Mul(lot.Qty).Div(e.Qty)
+			}
+			remaining = remaining.Sub(share)
+			gain := share.Sub(lot.Cost)
+			l.realized = l.realized.Add(gain)
+			l.disposals = append(l.disposals, Disposal{
+				Ticket:   e.Ticket,
+				Time:     e.Executed,
+				Lot:      lot,
+				Proceeds: share,
+				Gain:     gain,
+			})
+		}
+	default:
+		return fmt.Errorf("ticket %d: unknown side %q", e.Ticket, e.Side)
+	}
+	return nil
+}
+
+// AddWithdrawal records a withdrawal. Withdrawn BTC leaves at cost and
+// realizes no gain; the fee is recorded in fees at its cost.
+func (l *Ledger) AddWithdrawal(w Withdrawal) error {
+	total := w.Amount.Add(w.Fee)
+	switch w.Currency {
+	case BTC:
+		parts, err := l.take(total)
+		if err != nil {
+			return fmt.Errorf("withdrawal %s: %v", w.ID, err)
+		}
+		if !w.Fee.IsZero() {
+			var cost decimal.Decimal
+			for _, lot := range parts {
+				cost = cost.Add(lot.Cost)
+			}
+			l.fees = l.fees.Add(cost.Mul(w.Fee).Div(total))
+		}
+		l.btc = l.btc.Sub(total)
+	case Fiat:
+		l.fiat = l.fiat.Sub(total)
+		l.fees = l.fees.Add(w.Fee)
+	default:
+		return fmt.Errorf("withdrawal %s: unknown currency %q", w.ID, w.Currency)
+	}
+	return nil
+}
+
+func (l *Ledger) addLot(lot Lot) {
+	if l.method != AverageCost {
+		l.lots = append(l.lots, lot)
+		return
+	}
+	if len(l.lots) == 0 {
+		lot.Ticket = 0
+		l.lots = []Lot{lot}
+		return
+	}
+	pool := &l.lots[0]
+	pool.Qty = pool.Qty.Add(lot.Qty)
+	pool.Cost = pool.Cost.Add(lot.Cost)
+}
+
+// take removes qty BTC from the lots in the order given by the method and
+// returns the parts removed.
+func (l *Ledger) take(qty decimal.Decimal) ([]Lot, error) {
+	if qty.GreaterThan(l.btc) {
+		return nil, ErrInsufficientBTC
+	}
+	var parts []Lot
+	for qty.IsPositive() {
+		i := 0
+		if l.method == LIFO {
+			i = len(l.lots) - 1
+		}
+		lot := &l.lots[i]
+		n := decimal.Min(qty, lot.Qty)
+		parts = append(parts, lot.split(n))
+		qty = qty.Sub(n)
+		if lot.Qty.IsZero() && l.method != AverageCost {
+			l.lots = append(l.lots[:i], l.lots[i+1:]...)
+		}
+	}
+	return parts, nil
+}
+
+// Lots returns the open lots, oldest first.
+func (l *Ledger) Lots() []Lot {
+	res := []Lot{}
+	for _, lot := range l.lots {
+		if !lot.Qty.IsZero() {
+			res = append(res, lot)
+		}
+	}
+	return res
+}
+
+// Disposals returns all disposals in the order they happened.
+func (l *Ledger) Disposals() []Disposal {
+	return append([]Disposal{}, l.disposals...)
+}
+
+// Summarize returns the positions and P&L with BTC marked at mark.
+func (l *Ledger) Summarize(mark decimal.Decimal) Summary {
+	var basis decimal.Decimal
+	for _, lot := range l.lots {
+		basis = basis.Add(lot.Cost)
+	}
+	value := l.btc.Mul(mark)
+	return Summary{
+		Method:     l.method.String(),
+		BTC:        l.btc,
+		Fiat:       l.fiat,
+		CostBasis:  basis,
+		Mark:       mark,
+		Value:      value,
+		Realized:   l.realized,
+		Unrealized: value.Sub(basis),
+		Fees:       l.fees,
+	}
+}
+
+// MarkLast returns the last traded price of ticker.
+func MarkLast(ticker fyb.Ticker) decimal.Decimal {
+	return ticker.Last
+}
+
+// MarkMid returns the mid price between the best bid and ask of ticker.
+func MarkMid(ticker fyb.Ticker) decimal.Decimal {
+	return ticker.Bid.Add(ticker.Ask).Div(decimal.NewFromInt(2))
+}
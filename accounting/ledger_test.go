@@ -0,0 +1,69 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func exec(ticket int64, side Side, price, qty string, at int64) Execution {
+	return Execution{Ticket: ticket, Side: side, Price: d(price), Qty: d(qty), Executed: time.Unix(at, 0)}
+}
+
+func TestLedgerMethods(t *testing.T) {
+	execs := []Execution{
+		exec(3, Sell, "300", "1.5", 300),
+		exec(1, Buy, "100", "1", 100),
+		exec(2, Buy, "200", "1", 200),
+	}
+	for _, tc := range []struct {
+		method               Method
+		realized, unrealized string
+		disposals            int
+	}{
+		{FIFO, "250", "100", 2},
+		{LIFO, "200", "150", 2},
+		{AverageCost, "225", "125", 1},
+	} {
+		l := NewLedger(tc.method)
+		require.NoError(t, l.Ingest(execs, nil))
+		s := l.Summarize(MarkLast(fyb.Ticker{Last: d("400")}))
+		require.True(t, d(tc.realized).Equal(s.Realized), "%v realized %v", tc.method, s.Realized)
+		require.True(t, d(tc.unrealized).Equal(s.Unrealized), "%v unrealized %v", tc.method, s.Unrealized)
+		require.True(t, d("0.5").Equal(s.BTC))
+		require.True(t, d("150").Equal(s.Fiat))
+		require.Len(t, l.Disposals(), tc.disposals)
+		require.Len(t, l.Lots(), 1)
+	}
+}
+
+func TestLedgerWithdrawalsAndFees(t *testing.T) {
+	l := NewLedger(FIFO)
+	buy := exec(1, Buy, "100", "1", 100)
+	buy.Fee = d("1")
+	sell := exec(2, Sell, "200", "0.5", 300)
+	sell.Fee = d("0.5")
+	err := l.Ingest([]Execution{buy, sell}, []Withdrawal{
+		{ID: "w1", Time: time.Unix(200, 0), Currency: BTC, Amount: d("0.09"), Fee: d("0.01")},
+		{ID: "w2", Time: time.Unix(400, 0), Currency: Fiat, Amount: d("50"), Fee: d("1")},
+	})
+	require.NoError(t, err)
+
+	s := l.Summarize(MarkMid(fyb.Ticker{Bid: d("190"), Ask: d("210")}))
+	require.True(t, d("0.4").Equal(s.BTC))
+	require.True(t, d("-52.5").Equal(s.Fiat)) // -101 + 99.5 - 51
+	// The sold half of the remaining 0.9 BTC lot cost 50.5.
+	require.True(t, d("49").Equal(s.Realized), "%v", s.Realized)
+	require.True(t, d("40.4").Equal(s.CostBasis), "%v", s.CostBasis)
+	require.True(t, d("3.51").Equal(s.Fees), "%v", s.Fees) // 1 + 1.01 + 0.5 + 1
+
+	err = l.AddExecution(exec(3, Sell, "200", "1", 500))
+	require.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// Package accounting turns FYB order history and public trades into
+// auditable records: fills reconstructed per order ticket, positions and
+// profit and loss, and exports for finance and tax reporting.
+package accounting
+
+import (
+	"sort"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Side is the side of an execution.
+type Side string
+
+// Sides, as in FYB's order type field.
+const (
+	Buy  Side = "B"
+	Sell Side = "S"
+)
+
+// Execution is one of our executed orders.
+type Execution struct {
+	Ticket   int64           `json:"ticket"`
+	Side     Side            `json:"side"`
+	Price    decimal.Decimal `json:"price"`
+	Qty      decimal.Decimal `json:"qty"`
+	Created  time.Time       `json:"created"`
+	Executed time.Time       `json:"executed"`
+	// Fee is the fiat fee charged for the execution. FYB's order history
+	// does not report fees, so Executions leaves it zero.
+	Fee decimal.Decimal `json:"fee"`
+}
+
+// Executions returns the orders of history that have an execution time,
+// oldest execution first. Filter cancelled orders out beforehand, e.g. with
+// fyb.HistoryFilter.Status.
+func Executions(history []fyb.HistoryOrder) ([]Execution, error) {
+	var res []Execution
+	for _, o := range history {
+		if o.DateExecuted == 0 {
+			continue
+		}
+		price, err := fyb.ParseAmount(o.Price)
+		if err != nil {
+			return nil, err
+		}
+		qty, err := fyb.ParseAmount(o.Qty)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Execution{
+			Ticket:   o.Ticket,
+			Side:     Side(o.Type),
+			Price:    price,
+			Qty:      qty,
+			Created:  time.Unix(o.DateCreated, 0).UTC(),
+			Executed: time.Unix(o.DateExecuted, 0).UTC(),
+		})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Executed.Before(res[j].Executed) })
+	return res, nil
+}
+
+// Fill is a public trade attributed to one of our orders.
+type Fill struct {
+	TID    int64           `json:"tid"`
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+	Time   time.Time       `json:"time"`
+}
+
+// OrderFills is an execution with the trades that filled it.
+type OrderFills struct {
+	Execution
+	Fills  []Fill          `json:"fills"`
+	Filled decimal.Decimal `json:"filled"`
+}
+
+// Complete reports whether the fills add up to the order quantity.
+func (o OrderFills) Complete() bool {
+	return o.Filled.Equal(o.Qty)
+}
+
+// Report is the result of a reconciliation.
+type Report struct {
+	// Orders holds every execution with the fills found for it, in
+	// execution order.
+	Orders []OrderFills `json:"orders"`
+	// Unmatched holds the executions whose fills do not add up to their
+	// quantity. They are also in Orders with the partial fills found.
+	Unmatched []Execution `json:"unmatched"`
+}
+
+// Reconciler links executions to public trades.
+//
+// A trade can fill an execution if its price equals the order price and it
+// happened between the order's creation and its execution, widened by the
+// window on both sides to allow for clock skew. Executions are processed
+// oldest first; each takes a combination of candidate trades adding up to
+// its quantity, preferring trades close to its execution time. Each trade
+// fills at most one order.
+type Reconciler struct {
+	window time.Duration
+}
+
+// NewReconciler returns a Reconciler allowing window of clock skew.
+func NewReconciler(window time.Duration) *Reconciler {
+	return &Reconciler{window: window}
+}
+
+// Reconcile matches execs against trades.
+func (r *Reconciler) Reconcile(execs []Execution, trades fyb.Trades) Report {
+	used := map[int64]bool{}
+	report := Report{Orders: []OrderFills{}, Unmatched: []Execution{}}
+	for _, e := range execs {
+		from := e.Created.Add(-r.window).Unix()
+		to := e.Executed.Add(r.window).Unix()
+		executed := e.Executed.Unix()
+
+		var candidates []fyb.Trade
+		for _, t := range trades {
+			if !used[t.TID] && t.Date >= from && t.Date <= to && t.Price.Equal(e.Price) {
+				candidates = append(candidates, t)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return abs(candidates[i].Date-executed) < abs(candidates[j].Date-executed)
+		})
+
+		of := OrderFills{Execution: e, Fills: []Fill{}}
+		for _, t := range pick(candidates, e.Qty) {
+			used[t.TID] = true
+			of.Filled = of.Filled.Add(t.Amount)
+			of.Fills = append(of.Fills, Fill{
+				TID:    t.TID,
+				Price:  t.Price,
+				Amount: t.Amount,
+				Time:   time.Unix(t.Date, 0).UTC(),
+			})
+		}
+		sort.Slice(of.Fills, func(i, j int) bool { return of.Fills[i].TID < of.Fills[j].TID })
+
+		report.Orders = append(report.Orders, of)
+		if !of.Complete() {
+			report.Unmatched = append(report.Unmatched, e)
+		}
+	}
+	return report
+}
+
+// maxSearch bounds the number of steps pick spends looking for an exact
+// combination of trades.
+const maxSearch = 10000
+
+// pick chooses trades from candidates, which are ordered by preference,
+// whose amounts add up to qty. If no such combination is found it falls
+// back to greedily taking the preferred trades that fit.
+func pick(candidates []fyb.Trade, qty decimal.Decimal) []fyb.Trade {
+	var chosen []fyb.Trade
+	steps := 0
+	var search func(i int, remaining decimal.Decimal) bool
+	search = func(i int, remaining decimal.Decimal) bool {
+		if remaining.IsZero() {
+			return true
+		}
+		steps++
+		if i == len(candidates) || steps > maxSearch {
+			return false
+		}
+		if t := candidates[i]; t.Amount.LessThanOrEqual(remaining) {
+			chosen = append(chosen, t)
+			if search(i+1, remaining.Sub(t.Amount)) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return search(i+1, remaining)
+	}
+	if search(0, qty) {
+		return chosen
+	}
+
+	chosen = nil
+	for _, t := range candidates {
+		if t.Amount.LessThanOrEqual(qty) {
+			chosen = append(chosen, t)
+			qty = qty.Sub(t.Amount)
+		}
+	}
+	return chosen
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
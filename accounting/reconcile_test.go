@@ -0,0 +1,54 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func trade(tid, date int64, price, amount string) fyb.Trade {
+	return fyb.Trade{TID: tid, Date: date, Price: decimal.RequireFromString(price), Amount: decimal.RequireFromString(amount)}
+}
+
+func TestReconcile(t *testing.T) {
+	execs, err := Executions([]fyb.HistoryOrder{
+		{DateCreated: 1100, DateExecuted: 1200, Price: "S$3.00", Qty: "2.00000000BTC", Status: "F", Type: "S", Ticket: 12},
+		{DateCreated: 1000, DateExecuted: 1050, Price: "S$3.00", Qty: "1.50000000BTC", Status: "F", Type: "B", Ticket: 11},
+		{DateCreated: 1000, Price: "S$2.00", Qty: "1.00000000BTC", Status: "A", Type: "B", Ticket: 10},
+		{DateCreated: 1300, DateExecuted: 1310, Price: "S$4.00", Qty: "1.00000000BTC", Status: "F", Type: "B", Ticket: 13},
+	})
+	require.NoError(t, err)
+	require.Len(t, execs, 3)
+	require.Equal(t, int64(11), execs[0].Ticket)
+	require.Equal(t, Buy, execs[0].Side)
+
+	trades := fyb.Trades{
+		trade(1, 1010, "3.00", "1.0"),
+		trade(2, 1049, "3.00", "0.5"),
+		trade(3, 1050, "2.90", "0.5"),  // other price
+		trade(4, 1150, "3.00", "2.0"),  // order 12
+		trade(5, 1199, "3.00", "0.25"), // too much for 12 once 4 is taken
+		trade(6, 1400, "4.00", "1.0"),  // outside the window of 13
+	}
+	report := NewReconciler(10*time.Second).Reconcile(execs, trades)
+
+	require.Len(t, report.Orders, 3)
+	o := report.Orders[0]
+	require.True(t, o.Complete())
+	require.Len(t, o.Fills, 2)
+	require.Equal(t, int64(1), o.Fills[0].TID)
+	require.Equal(t, int64(2), o.Fills[1].TID)
+
+	o = report.Orders[1]
+	require.True(t, o.Complete())
+	require.Len(t, o.Fills, 1)
+	require.Equal(t, int64(4), o.Fills[0].TID)
+
+	require.False(t, report.Orders[2].Complete())
+	require.Empty(t, report.Orders[2].Fills)
+	require.Len(t, report.Unmatched, 1)
+	require.Equal(t, int64(13), report.Unmatched[0].Ticket)
+}
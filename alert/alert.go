@@ -0,0 +1,267 @@
+// Package alert evaluates price alert rules against FYB market data and
+// sends notifications when they trigger.
+//
+//	engine := alert.NewEngine(alert.NewWriterNotifier(os.Stdout))
+//	engine.Add(alert.Rule{Name: "btc above 20k", Condition: alert.LastCrossesAbove(d("20000")), Cooldown: time.Hour})
+//	engine.Add(alert.Rule{Name: "wide spread", Condition: alert.SpreadExceeds(d("50")), Cooldown: 10 * time.Minute})
+//	stop := engine.Watch(api, tracker, 10*time.Second)
+//	defer stop()
+package alert
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Alert is a triggered rule.
+type Alert struct {
+	Rule    string    `json:"rule"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Rule fires Condition at most once per Cooldown.
+type Rule struct {
+	Name      string
+	Condition Condition
+	Cooldown  time.Duration
+}
+
+// PricePoint is a last traded price from the ticker.
+type PricePoint struct {
+	Time  time.Time
+	Price decimal.Decimal
+}
+
+// Market is the market data conditions are checked against.
+type Market struct {
+	Now       time.Time
+	Ticker    fyb.Ticker
+	HasTicker bool
+	Book      fyb.OrderBook
+	HasBook   bool
+	Prices    []PricePoint // last prices within the retention period, oldest first
+	Trades    fyb.Trades   // trades within the retention period, oldest first
+}
+
+type rule struct {
+	Rule
+	fired time.Time
+}
+
+// Engine checks rules whenever market data is updated and notifies when a
+// rule triggers. It keeps an hour of prices and trades by default.
+type Engine struct {
+	notifiers []Notifier
+
+	// OnError, if set, is called with polling and notification errors.
+	OnError func(err error)
+
+	mu        sync.Mutex
+	rules     []*rule
+	market    Market
+	retention time.Duration
+	queueSize int
+	queue     chan Alert // set while notifications are dispatched by Watch
+	now       func() time.Time
+}
+
+// NewEngine returns an Engine sending alerts to notifiers.
+func NewEngine(notifiers ...Notifier) *Engine {
+	return &Engine{
+		notifiers: notifiers,
+		retention: time.Hour,
+		queueSize: 64,
+		now:       time.Now,
+	}
+}
+
+// SetRetention sets how long prices and trades are kept. It must cover the
+// longest window used by a condition.
+func (e *Engine) SetRetention(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.retention = d
+}
+
+// SetQueueSize sets how many alerts may wait for the notifiers while the
+// engine is watching. Alerts beyond that are dropped and reported to
+// OnError. Default is 64. It takes effect on the next Watch.
+func (e *Engine) SetQueueSize(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queueSize = n
+}
+
+// Add adds a rule.
+func (e *Engine) Add(r Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append(e.rules, &rule{Rule: r})
+}
+
+// UpdateTicker records a ticker and checks the rules.
+func (e *Engine) UpdateTicker(t fyb.Ticker) {
+	e.update(func(m *Market) {
+		m.Ticker, m.HasTicker = t, true
+		m.Prices = append(m.Prices, PricePoint{m.Now, t.Last})
+	})
+}
+
+// UpdateBook records an order book and checks the rules.
+func (e *Engine) UpdateBook(b fyb.OrderBook) {
+	e.update(func(m *Market) {
+		m.Book, m.HasBook = b, true
+	})
+}
+
+// UpdateTrades records new trades and checks the rules.
+func (e *Engine) UpdateTrades(trades fyb.Trades) {
+	e.update(func(m *Market) {
+		m.Trades = append(m.Trades, trades...)
+	})
+}
+
+func (e *Engine) update(fn func(m *Market)) {
+	e.mu.Lock()
+	m := &e.market
+	m.Now = e.now()
+	fn(m)
+	e.trim()
+
+	var alerts []Alert
+	for _, r := range e.rules {
+		msg, ok := r.Condition.Check(m)
+		if !ok || !r.fired.IsZero() && m.Now.Sub(r.fired) < r.Cooldown {
+			continue
+		}
+		r.fired = m.Now
+		alerts = append(alerts, Alert{Rule: r.Name, Message: msg, Time: m.Now})
+	}
+	queue := e.queue
+	var dropped []Alert
+	if queue != nil {
+		// Queued under e.mu, so that stopDispatch cannot close the queue
+		// in between.
+		for _, a := range alerts {
+			select {
+			case queue <- a:
+			default:
+				dropped = append(dropped, a)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	for _, a := range dropped {
+		e.error(fmt.Errorf("alert queue full, dropped alert %q", a.Rule))
+	}
+	if queue == nil {
+		for _, a := range alerts {
+			e.notify(a)
+		}
+	}
+}
+
+func (e *Engine) notify(a Alert) {
+	for _, n := range e.notifiers {
+		if err := n.Notify(a); err != nil {
+			e.error(err)
+		}
+	}
+}
+
+// startDispatch sends alerts to the notifiers from a separate goroutine
+// until the returned function is called, which waits for the queued
+// alerts to be sent.
+func (e *Engine) startDispatch() (stop func()) {
+	e.mu.Lock()
+	queue := make(chan Alert, e.queueSize)
+	e.queue = queue
+	e.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for a := range queue {
+			e.notify(a)
+		}
+	}()
+	return func() {
+		e.mu.Lock()
+		e.queue = nil
+		e.mu.Unlock()
+		close(queue)
+		<-done
+	}
+}
+
+// trim drops prices and trades older than the retention period. Called
+// with e.mu held.
+func (e *Engine) trim() {
+	m := &e.market
+	cutoff := m.Now.Add(-e.retention)
+	i := 0
+	for i < len(m.Prices) && m.Prices[i].Time.Before(cutoff) {
+		i++
+	}
+	m.Prices = m.Prices[i:]
+	i = 0
+	for i < len(m.Trades) && m.Trades[i].Date < cutoff.Unix() {
+		i++
+	}
+	m.Trades = m.Trades[i:]
+}
+
+func (e *Engine) error(err error) {
+	if e.OnError != nil {
+		e.OnError(err)
+	}
+}
+
+// Watch feeds the engine from tracker's order book and trades and polls
+// the ticker every interval until the returned function is called. The
+// tracker must be started separately.
+//
+// While watching, notifiers run on their own goroutine, so a slow
+// notifier does not hold up market data. Stopping waits for the queued
+// alerts to be sent.
+func (e *Engine) Watch(api *fyb.Fyb, tracker *fyb.Tracker, interval time.Duration) (stop func()) {
+	books, unsubBooks := tracker.SubscribeBook()
+	trades, unsubTrades := tracker.SubscribeTrades()
+	stopDispatch := e.startDispatch()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer stopDispatch()
+		defer unsubBooks()
+		defer unsubTrades()
+		poll := time.NewTicker(interval)
+		defer poll.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case book := <-books:
+				e.UpdateBook(book)
+			case batch := <-trades:
+				e.UpdateTrades(batch)
+			case <-poll.C:
+				ticker, err := api.GetTicker()
+				if err != nil {
+					e.error(err)
+					continue
+				}
+				e.UpdateTicker(ticker)
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
@@ -0,0 +1,138 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+type fakeMailer struct {
+	from string
+	to   []string
+	msg  string
+}
+
+func (m *fakeMailer) SendMail(from string, to []string, msg []byte) error {
+	m.from, m.to, m.msg = from, to, string(msg)
+	return nil
+}
+
+func TestEngine(t *testing.T) {
+	var alerts []Alert
+	engine := NewEngine(NotifierFunc(func(a Alert) error {
+		alerts = append(alerts, a)
+		return nil
+	}))
+	now := time.Unix(1000, 0)
+	engine.now = func() time.Time { return now }
+	engine.Add(Rule{Name: "cross", Condition: LastCrossesAbove(d("100")), Cooldown: time.Minute})
+	engine.Add(Rule{Name: "spread", Condition: SpreadExceeds(d("5")), Cooldown: time.Minute})
+	engine.Add(Rule{Name: "move", Condition: PercentMove(d("10"), 5*time.Minute), Cooldown: time.Hour})
+	engine.Add(Rule{Name: "volume", Condition: VolumeSpike(d("3"), time.Minute, 10*time.Minute)})
+
+	names := func() []string {
+		var res []string
+		for _, a := range alerts {
+			res = append(res, a.Rule)
+		}
+		alerts = nil
+		return res
+	}
+	step := func(d time.Duration) { now = now.Add(d) }
+
+	engine.UpdateTicker(fyb.Ticker{Last: d("95")})
+	step(time.Minute)
+	engine.UpdateTicker(fyb.Ticker{Last: d("101")})
+	require.Equal(t, []string{"cross"}, names())
+
+	step(time.Second)
+	engine.UpdateTicker(fyb.Ticker{Last: d("99")})
+	engine.UpdateTicker(fyb.Ticker{Last: d("105")})
+	require.Equal(t, []string{"move"}, names(), "cross is cooling down, 95 -> 105 is over 10%")
+
+	book := fyb.OrderBook{
+		Asks: []fyb.PriceAmount{{Price: d("110"), Amount: d("1")}},
+		Bids: []fyb.PriceAmount{{Price: d("100"), Amount: d("1")}},
+	}
+	engine.UpdateBook(book)
+	require.Equal(t, []string{"spread"}, names())
+	engine.UpdateBook(book)
+	require.Empty(t, names())
+	step(time.Minute)
+	engine.UpdateBook(book)
+	require.Equal(t, []string{"spread"}, names())
+
+	trade := func(ago time.Duration, amount string) fyb.Trade {
+		return fyb.Trade{Date: now.Add(-ago).Unix(), Amount: d(amount)}
+	}
+	engine.UpdateTrades(fyb.Trades{trade(9*time.Minute, "1"), trade(5*time.Minute, "1")})
+	require.Empty(t, names())
+	engine.UpdateTrades(fyb.Trades{trade(10*time.Second, "0.7")})
+	require.Equal(t, []string{"volume"}, names(), "0.7 is 3.5x the average of 0.2 per minute")
+}
+
+func TestNotifiers(t *testing.T) {
+	a := Alert{Rule: "spread", Message: "spread 10 exceeds 5", Time: time.Unix(1000, 0).UTC()}
+
+	var got Alert
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer ts.Close()
+	require.NoError(t, NewWebhookNotifier(ts.URL).Notify(a))
+	require.Equal(t, a, got)
+
+	mailer := &fakeMailer{}
+	require.NoError(t, NewEmailNotifier(mailer, "fyb@example.com", "ops@example.com").Notify(a))
+	require.Equal(t, []string{"ops@example.com"}, mailer.to)
+	require.Contains(t, mailer.msg, "Subject: FYB alert: spread\r\n")
+	require.True(t, strings.HasSuffix(mailer.msg, "\r\nspread 10 exceeds 5\r\n"))
+
+	var buf strings.Builder
+	require.NoError(t, NewWriterNotifier(&buf).Notify(a))
+	require.Equal(t, "1970-01-01T00:16:40Z [spread] spread 10 exceeds 5\n", buf.String())
+}
+
+func TestEngineQueuesNotifications(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var sent []string
+	engine := NewEngine(NotifierFunc(func(a Alert) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, a.Message)
+		return nil
+	}))
+	var errs []error
+	engine.OnError = func(err error) { errs = append(errs, err) }
+	engine.SetQueueSize(2)
+	engine.Add(Rule{Name: "any", Condition: ConditionFunc(func(m *Market) (string, bool) {
+		return m.Ticker.Last.String(), true
+	})})
+
+	stop := engine.startDispatch()
+	// The first alert blocks the notifier, two wait and the last is dropped.
+	for _, last := range []string{"1", "2", "3", "4"} {
+		engine.UpdateTicker(fyb.Ticker{Last: d(last)})
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], `alert queue full, dropped alert "any"`)
+
+	close(release)
+	stop()
+	require.Equal(t, []string{"1", "2", "3"}, sent)
+}
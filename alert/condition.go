@@ -0,0 +1,128 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Condition decides whether a rule triggers. Check is called after every
+// market data update and returns a description of what happened. Checks
+// of one Engine are not run concurrently, so conditions may keep state.
+type Condition interface {
+	Check(m *Market) (msg string, ok bool)
+}
+
+// ConditionFunc adapts a function to the Condition interface.
+type ConditionFunc func(m *Market) (string, bool)
+
+// Check calls f(m).
+func (f ConditionFunc) Check(m *Market) (string, bool) {
+	return f(m)
+}
+
+// crossing triggers when the last price moves to the other side of a level.
+type crossing struct {
+	level decimal.Decimal
+	above bool
+	prev  decimal.Decimal
+	seen  bool
+}
+
+// LastCrossesAbove triggers when the last price rises from below level to
+// level or above.
+func LastCrossesAbove(level decimal.Decimal) Condition {
+	return &crossing{level: level, above: true}
+}
+
+// LastCrossesBelow triggers when the last price falls from above level to
+// level or below.
+func LastCrossesBelow(level decimal.Decimal) Condition {
+	return &crossing{level: level}
+}
+
+func (c *crossing) Check(m *Market) (string, bool) {
+	if !m.HasTicker {
+		return "", false
+	}
+	last := m.Ticker.Last
+	prev, seen := c.prev, c.seen
+	c.prev, c.seen = last, true
+	if !seen {
+		return "", false
+	}
+	if c.above && prev.LessThan(c.level) && last.GreaterThanOrEqual(c.level) {
+		return fmt.Sprintf("last %s crossed above %s", last, c.level), true
+	}
+	if !c.above && prev.GreaterThan(c.level) && last.LessThanOrEqual(c.level) {
+		return fmt.Sprintf("last %s crossed below %s", last, c.level), true
+	}
+	return "", false
+}
+
+// SpreadExceeds triggers while the spread between the best ask and bid of
+// the order book is larger than max.
+func SpreadExceeds(max decimal.Decimal) Condition {
+	return ConditionFunc(func(m *Market) (string, bool) {
+		if !m.HasBook || len(m.Book.Asks) == 0 || len(m.Book.Bids) == 0 {
+			return "", false
+		}
+		spread := m.Book.Asks[0].Price.Sub(m.Book.Bids[0].Price)
+		if spread.GreaterThan(max) {
+			return fmt.Sprintf("spread %s exceeds %s", spread, max), true
+		}
+		return "", false
+	})
+}
+
+// PercentMove triggers while the last price differs by pct percent or more
+// from the oldest last price within window.
+func PercentMove(pct decimal.Decimal, window time.Duration) Condition {
+	return ConditionFunc(func(m *Market) (string, bool) {
+		if len(m.Prices) < 2 {
+			return "", false
+		}
+		cutoff := m.Now.Add(-window)
+		var from PricePoint
+		for _, p := range m.Prices {
+			if !p.Time.Before(cutoff) {
+				from = p
+				break
+			}
+		}
+		if from.Price.IsZero() {
+			return "", false
+		}
+		last := m.Prices[len(m.Prices)-1].Price
+		move := last.Sub(from.Price).Div(from.Price).Mul(decimal.NewFromInt(100))
+		if move.Abs().GreaterThanOrEqual(pct) {
+			return fmt.Sprintf("last moved %s%% to %s within %v", move.Round(2), last, window), true
+		}
+		return "", false
+	})
+}
+
+// VolumeSpike triggers while the volume traded within window is at least
+// factor times the average volume per window over the preceding baseline
+// period. The engine's retention must cover window plus baseline.
+func VolumeSpike(factor decimal.Decimal, window, baseline time.Duration) Condition {
+	return ConditionFunc(func(m *Market) (string, bool) {
+		recentFrom := m.Now.Add(-window).Unix()
+		baseFrom := m.Now.Add(-window - baseline).Unix()
+		var recent, base decimal.Decimal
+		for _, t := range m.Trades {
+			switch {
+			case t.Date >= recentFrom:
+				recent = recent.Add(t.Amount)
+			case t.Date >= baseFrom:
+				base = base.Add(t.Amount)
+			}
+		}
+		avg := base.Mul(decimal.NewFromInt(int64(window))).Div(decimal.NewFromInt(int64(baseline)))
+		if !avg.IsPositive() || recent.LessThan(avg.Mul(factor)) {
+			return "", false
+		}
+		return fmt.Sprintf("volume %s within %v is %sx the average", recent, window, recent.Div(avg).Round(1)), true
+	})
+}
@@ -0,0 +1,114 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(a Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(a Alert) error
+
+// Notify calls f(a).
+func (f NotifierFunc) Notify(a Alert) error {
+	return f(a)
+}
+
+// WriterNotifier writes one line per alert, e.g. to os.Stdout.
+type WriterNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterNotifier returns a WriterNotifier writing to w.
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+	return &WriterNotifier{w: w}
+}
+
+// Notify implements Notifier.
+func (n *WriterNotifier) Notify(a Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err := fmt.Fprintf(n.w, "%s [%s] %s\n", a.Time.Format(time.RFC3339), a.Rule, a.Message)
+	return err
+}
+
+// WebhookNotifier posts every alert as JSON to a URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a WebhookNotifier posting to url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier.
+func (n *WebhookNotifier) Notify(a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+// Mailer sends an email message. SMTPMailer implements it with net/smtp.
+type Mailer interface {
+	SendMail(from string, to []string, msg []byte) error
+}
+
+// SMTPMailer sends mail through an SMTP server.
+type SMTPMailer struct {
+	Addr string    // host:port
+	Auth smtp.Auth // may be nil
+}
+
+// SendMail implements Mailer.
+func (m SMTPMailer) SendMail(from string, to []string, msg []byte) error {
+	return smtp.SendMail(m.Addr, m.Auth, from, to, msg)
+}
+
+// EmailNotifier sends every alert as an email.
+type EmailNotifier struct {
+	mailer Mailer
+	from   string
+	to     []string
+}
+
+// NewEmailNotifier returns an EmailNotifier sending from from to to.
+func NewEmailNotifier(mailer Mailer, from string, to ...string) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer, from: from, to: to}
+}
+
+// Notify implements Notifier.
+func (n *EmailNotifier) Notify(a Alert) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: FYB alert: %s\r\n", a.Rule)
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "\r\n%s\r\n", a.Message)
+	return n.mailer.SendMail(n.from, n.to, msg.Bytes())
+}
@@ -0,0 +1,304 @@
+package fyb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceChangeKind classifies a balance change.
+type BalanceChangeKind string
+
+// Kinds of balance changes.
+const (
+	BalanceFill       BalanceChangeKind = "fill"       // explained by executed orders
+	BalanceDeposit    BalanceChangeKind = "deposit"    // unexplained increase
+	BalanceWithdrawal BalanceChangeKind = "withdrawal" // unexplained decrease
+)
+
+// Currencies of a BalanceChange. The fiat balance is the account's sgdBal
+// or sekBal, depending on the market.
+const (
+	CurrencyBTC  = "BTC"
+	CurrencyFiat = "FIAT"
+)
+
+// BalanceChange is a change of the BTC or fiat balance seen by a
+// BalanceWatcher.
+type BalanceChange struct {
+	Kind     BalanceChangeKind
+	Currency string
+	Amount   decimal.Decimal // signed
+	Balance  decimal.Decimal // balance after all changes of the poll
+	Orders   []HistoryOrder  // for fills, the orders executed since the last poll, with the newly executed Qty
+	Address  string          // for BTC deposits, the account's deposit address
+	Time     time.Time
+}
+
+func (c BalanceChange) String() string {
+	return fmt.Sprintf("%s %s %s (balance %s)", c.Kind, c.Amount, c.Currency, c.Balance)
+}
+
+// BalanceWatcher polls the account balances and reports every change,
+// split into the part explained by orders executed since the previous poll
+// and the rest, which is reported as a deposit or withdrawal.
+//
+// Fiat differences up to the tolerance (default 0.01) are attributed to the
+// fills, to absorb fees and rounding.
+type BalanceWatcher struct {
+	api          *Fyb
+	interval     time.Duration
+	historyLimit int64
+	tolerance    decimal.Decimal
+
+	// OnError, if set, is called with errors from polling. Set it before Start.
+	OnError func(err error)
+
+	mu       sync.Mutex
+	handlers []func(BalanceChange)
+	started  bool
+	btc      decimal.Decimal
+	fiat     decimal.Decimal
+	seen     map[int64]decimal.Decimal // executed qty per ticket in the last history
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewBalanceWatcher returns a BalanceWatcher polling api every interval.
+// The first poll only records the starting balances.
+func NewBalanceWatcher(api *Fyb, interval time.Duration) *BalanceWatcher {
+	return &BalanceWatcher{
+		api:          api,
+		interval:     interval,
+		historyLimit: 50,
+		tolerance:    decimal.New(1, -2),
+		seen:         map[int64]decimal.Decimal{},
+	}
+}
+
+// SetHistoryLimit sets how many of the newest orders are read from the
+// order history on every poll. It must cover all orders executed between
+// two polls. Default is 50.
+func (w *BalanceWatcher) SetHistoryLimit(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.historyLimit = n
+}
+
+// SetFiatTolerance sets the largest fiat difference attributed to fills.
+func (w *BalanceWatcher) SetFiatTolerance(d decimal.Decimal) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tolerance = d
+}
+
+// OnChange registers fn to be called for every balance change.
+func (w *BalanceWatcher) OnChange(fn func(BalanceChange)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// Start begins polling in the background.
+func (w *BalanceWatcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.loop(w.stop, w.done)
+}
+
+// Stop stops polling and waits for the current poll to finish.
+func (w *BalanceWatcher) Stop() {
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (w *BalanceWatcher) loop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.Poll()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *BalanceWatcher) error(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// Poll fetches the balances once and reports changes. It is called by the
+// background loop but can also be used directly.
+//
+// The balances are read before and after the order history. If they moved
+// in between, a fill may be missing from the history, so the poll is
+// skipped and the change is reported by the next one.
+func (w *BalanceWatcher) Poll() {
+	before, err := w.accountInfo()
+	if err != nil {
+		w.error(err)
+		return
+	}
+	w.mu.Lock()
+	limit := w.historyLimit
+	w.mu.Unlock()
+	history, err := w.api.GetOrderHistory(limit)
+	if err == nil && history.Error != 0 {
+		err = fmt.Errorf("FYB error %d: %s", history.Error, history.Msg)
+	}
+	if err != nil {
+		// Retry on the next poll with the same starting balances.
+		w.error(err)
+		return
+	}
+	info, err := w.accountInfo()
+	if err != nil {
+		w.error(err)
+		return
+	}
+	currency := w.api.Currency()
+	fiat := info.FiatBal(currency)
+	if !info.BtcBal.Equal(before.BtcBal) || !fiat.Equal(before.FiatBal(currency)) {
+		return
+	}
+
+	executed, orders, err := executedQty(history.Orders)
+	if err != nil {
+		w.error(err)
+		return
+	}
+
+	w.mu.Lock()
+	started := w.started
+	dBTC, dFiat := info.BtcBal.Sub(w.btc), fiat.Sub(w.fiat)
+	var fills []HistoryOrder
+	for _, o := range orders {
+		if more := executed[o.Ticket].Sub(w.seen[o.Ticket]); more.IsPositive() {
+			o.Qty = more.String()
+			fills = append(fills, o)
+		}
+	}
+	// Only tickets still in the history can execute again, which keeps
+	// seen as small as the history.
+	w.seen = executed
+	w.btc, w.fiat = info.BtcBal, fiat
+	w.started = true
+	handlers := append([]func(BalanceChange){}, w.handlers...)
+	tolerance := w.tolerance
+	w.mu.Unlock()
+	if !started {
+		return
+	}
+
+	changes, err := classify(fills, dBTC, dFiat, tolerance)
+	if err != nil {
+		w.error(err)
+	}
+	now := time.Now()
+	for _, c := range changes {
+		c.Time = now
+		if c.Currency == CurrencyBTC {
+			c.Balance = info.BtcBal
+			if c.Kind == BalanceDeposit {
+				c.Address = info.BtcDeposit
+			}
+		} else {
+			c.Balance = fiat
+		}
+		for _, fn := range handlers {
+			fn(c)
+		}
+	}
+}
+
+func (w *BalanceWatcher) accountInfo() (AccountInfoResponse, error) {
+	info, err := w.api.GetAccountInfo()
+	if err == nil && info.Error != 0 {
+		err = fmt.Errorf("FYB error %d: %s", info.Error, info.Msg)
+	}
+	return info, err
+}
+
+// executedQty sums the executed quantity per ticket, as an order may be
+// executed in parts. It also returns one executed order per ticket, in
+// history order.
+func executedQty(history []HistoryOrder) (map[int64]decimal.Decimal, []HistoryOrder, error) {
+	executed := map[int64]decimal.Decimal{}
+	var orders []HistoryOrder
+	for _, o := range history {
+		if o.DateExecuted == 0 {
+			continue
+		}
+		qty, err := ParseAmount(o.Qty)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := executed[o.Ticket]; !ok {
+			orders = append(orders, o)
+		}
+		executed[o.Ticket] = executed[o.Ticket].Add(qty)
+	}
+	return executed, orders, nil
+}
+
+// classify splits balance deltas into changes explained by fills and the
+// unexplained rest.
+func classify(fills []HistoryOrder, dBTC, dFiat, tolerance decimal.Decimal) ([]BalanceChange, error) {
+	var fillBTC, fillFiat decimal.Decimal
+	for _, o := range fills {
+		price, err := ParseAmount(o.Price)
+		if err != nil {
+			return nil, err
+		}
+		qty, err := ParseAmount(o.Qty)
+		if err != nil {
+			return nil, err
+		}
+		switch o.Type {
+		case "B":
+			fillBTC, fillFiat = fillBTC.Add(qty), fillFiat.Sub(qty.Mul(price))
+		case "S":
+			fillBTC, fillFiat = fillBTC.Sub(qty), fillFiat.Add(qty.Mul(price))
+		}
+	}
+	if len(fills) > 0 && dFiat.Sub(fillFiat).Abs().LessThanOrEqual(tolerance) {
+		fillFiat = dFiat
+	}
+
+	var changes []BalanceChange
+	add := func(kind BalanceChangeKind, currency string, amount decimal.Decimal, orders []HistoryOrder) {
+		if !amount.IsZero() {
+			changes = append(changes, BalanceChange{Kind: kind, Currency: currency, Amount: amount, Orders: orders})
+		}
+	}
+	unexplained := func(currency string, amount decimal.Decimal) {
+		if amount.IsPositive() {
+			add(BalanceDeposit, currency, amount, nil)
+		} else {
+			add(BalanceWithdrawal, currency, amount, nil)
+		}
+	}
+	add(BalanceFill, CurrencyBTC, fillBTC, fills)
+	add(BalanceFill, CurrencyFiat, fillFiat, fills)
+	unexplained(CurrencyBTC, dBTC.Sub(fillBTC))
+	unexplained(CurrencyFiat, dFiat.Sub(fillFiat))
+	return changes, nil
+}
@@ -0,0 +1,151 @@
+package fyb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceWatcher(t *testing.T) {
+	var mu sync.Mutex
+	btc, fiat := "1.00000000", "100.00"
+	orders := `{"date_created":1,"date_executed":2,"price":"S$50.00","qty":"1.00000000BTC","status":"F","ticket":1,"type":"B"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/getaccinfo":
+			fmt.Fprintf(w, `{"accNo":1,"btcBal":"%s","btcDeposit":"1Dep","email":"a@b","error":0,"sgdBal":"%s"}`, btc, fiat)
+		case "/getorderhistory":
+			fmt.Fprintf(w, `{"error":0,"orders":[%s]}`, orders)
+		}
+	}))
+	defer ts.Close()
+
+	watcher := NewBalanceWatcher(New(ts.URL, "key", "secret"), 0)
+	var changes []string
+	var deposit BalanceChange
+	watcher.OnChange(func(c BalanceChange) {
+		changes = append(changes, c.String())
+		if c.Kind == BalanceDeposit {
+			deposit = c
+		}
+	})
+	watcher.Poll()
+	require.Empty(t, changes, "first poll sets the baseline")
+
+	// A sell of 0.5 at 60 with a small fee, plus a BTC deposit of 2.
+	mu.Lock()
+	btc, fiat = "2.50000000", "129.99"
+	orders = `{"date_created":3,"date_executed":4,"price":"S$60.00","qty":"0.50000000BTC","status":"F","ticket":2,"type":"S"},` + orders
+	mu.Unlock()
+	watcher.Poll()
+	require.Equal(t, []string{
+		"fill -0.5 BTC (balance 2.5)",
+		"fill 29.99 FIAT (balance 129.99)",
+		"deposit 2 BTC (balance 2.5)",
+	}, changes)
+	require.Equal(t, "1Dep", deposit.Address)
+
+	// Fiat leaves with no order executed.
+	changes = nil
+	mu.Lock()
+	fiat = "29.99"
+	mu.Unlock()
+	watcher.Poll()
+	require.Equal(t, []string{"withdrawal -100 FIAT (balance 29.99)"}, changes)
+
+	watcher.Poll()
+	require.Len(t, changes, 1, "unchanged balances are not reported")
+}
+
+func TestBalanceWatcherSEKAndRacingFill(t *testing.T) {
+	var mu sync.Mutex
+	btc, sek := "1.00000000", "1000.00"
+	orders := ""
+	var onHistory func()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getaccinfo"):
+			fmt.Fprintf(w, `{"accNo":1,"btcBal":"%s","error":0,"sgdBal":"0.00","sekBal":"%s"}`, btc, sek)
+		case strings.HasSuffix(r.URL.Path, "/getorderhistory"):
+			fmt.Fprintf(w, `{"error":0,"orders":[%s]}`, orders)
+			if onHistory != nil {
+				onHistory()
+				onHistory = nil
+			}
+		}
+	}))
+	defer ts.Close()
+
+	watcher := NewBalanceWatcher(New(ts.URL+"/api/SEK", "key", "secret"), 0)
+	var changes []string
+	watcher.OnChange(func(c BalanceChange) { changes = append(changes, c.String()) })
+	watcher.Poll()
+
+	// A buy executes right after the history was read.
+	mu.Lock()
+	onHistory = func() {
+		btc, sek = "1.10000000", "500.00"
+		orders = `{"date_created":1,"date_executed":2,"price":"kr5000.00","qty":"0.10000000BTC","status":"F","ticket":1,"type":"B"}`
+	}
+	mu.Unlock()
+	watcher.Poll()
+	require.Empty(t, changes, "the poll is skipped instead of reporting a withdrawal")
+
+	watcher.Poll()
+	require.Equal(t, []string{"fill 0.1 BTC (balance 1.1)", "fill -500 FIAT (balance 500)"}, changes)
+}
+
+func TestBalanceWatcherPartialFills(t *testing.T) {
+	var mu sync.Mutex
+	btc, fiat := "0.00000000", "100.00"
+	orders := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/getaccinfo":
+			fmt.Fprintf(w, `{"accNo":1,"btcBal":"%s","error":0,"sgdBal":"%s"}`, btc, fiat)
+		case "/getorderhistory":
+			fmt.Fprintf(w, `{"error":0,"orders":[%s]}`, orders)
+		}
+	}))
+	defer ts.Close()
+
+	watcher := NewBalanceWatcher(New(ts.URL, "key", "secret"), 0)
+	var changes []BalanceChange
+	watcher.OnChange(func(c BalanceChange) { changes = append(changes, c) })
+	watcher.Poll()
+
+	// The same order executes in two parts over two polls.
+	part := `{"date_created":1,"date_executed":%d,"price":"S$50.00","qty":"%sBTC","status":"F","ticket":1,"type":"B"}`
+	mu.Lock()
+	btc, fiat = "0.30000000", "85.00"
+	orders = fmt.Sprintf(part, 2, "0.30000000")
+	mu.Unlock()
+	watcher.Poll()
+	require.Len(t, changes, 2)
+	require.Equal(t, "fill 0.3 BTC (balance 0.3)", changes[0].String())
+
+	changes = nil
+	mu.Lock()
+	btc, fiat = "0.50000000", "75.00"
+	orders = fmt.Sprintf(part, 3, "0.20000000") + "," + orders
+	mu.Unlock()
+	watcher.Poll()
+	require.Len(t, changes, 2)
+	require.Equal(t, "fill 0.2 BTC (balance 0.5)", changes[0].String())
+	require.Equal(t, "fill -10 FIAT (balance 75)", changes[1].String())
+	require.Equal(t, "0.2", changes[0].Orders[0].Qty)
+
+	watcher.Poll()
+	require.Len(t, changes, 2, "nothing new executed")
+}
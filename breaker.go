@@ -0,0 +1,177 @@
+package fyb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+// Breaker states.
+const (
+	BreakerClosed   BreakerState = iota // requests flow normally
+	BreakerOpen                         // requests fail fast with *CircuitOpenError
+	BreakerHalfOpen                     // a limited number of probe requests are let through
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// CircuitOpenError is returned without contacting FYB while the breaker is
+// open.
+type CircuitOpenError struct {
+	RetryAfter time.Duration // time until the breaker lets a probe through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("FYB API circuit breaker is open, retry in %v", e.RetryAfter)
+}
+
+// Breaker is a circuit breaker for Client. It opens after a number of
+// consecutive timeouts, network errors or 5xx responses, fails fast while
+// open, and after a cooldown lets probe requests through to decide whether
+// to close again. Other errors, such as a rejected API key, show that FYB
+// is up and count as successes.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	probes    int
+	onChange  func(from, to BreakerState)
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	inFlight  int // probes currently running in half-open state
+	successes int // successful probes in half-open state
+}
+
+// NewBreaker returns a Breaker that opens after threshold consecutive
+// failures and stays open for cooldown. One successful probe closes it.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		probes:    1,
+		now:       time.Now,
+	}
+}
+
+// SetProbes sets how many probe requests must succeed in the half-open
+// state before the breaker closes. At most that many run at once.
+func (b *Breaker) SetProbes(n int) {
+	if n < 1 {
+		n = 1
+	}
+	b.mu.Lock()
+	b.probes = n
+	b.mu.Unlock()
+}
+
+// OnStateChange sets a callback invoked after every state change.
+func (b *Breaker) OnStateChange(fn func(from, to BreakerState)) {
+	b.mu.Lock()
+	b.onChange = fn
+	b.mu.Unlock()
+}
+
+// State returns the current state.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// setState changes the state and returns the callback to run once the
+// lock is released. Called with b.mu held.
+func (b *Breaker) setState(to BreakerState) func() {
+	from := b.state
+	if from == to {
+		return func() {}
+	}
+	b.state = to
+	b.failures, b.inFlight, b.successes = 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+	fn := b.onChange
+	return func() {
+		if fn != nil {
+			fn(from, to)
+		}
+	}
+}
+
+// allow reports whether a request may proceed.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	notify := func() {}
+	defer func() {
+		b.mu.Unlock()
+		notify()
+	}()
+
+	if b.state == BreakerOpen {
+		wait := b.cooldown - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			return &CircuitOpenError{RetryAfter: wait}
+		}
+		notify = b.setState(BreakerHalfOpen)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.inFlight+b.successes >= b.probes {
+			return &CircuitOpenError{}
+		}
+		b.inFlight++
+	}
+	return nil
+}
+
+// record reports the outcome of an allowed request by its error class.
+func (b *Breaker) record(class string) {
+	failed := class == ErrorClassTimeout || class == ErrorClassNetwork || class == ErrorClassServer
+
+	b.mu.Lock()
+	notify := func() {}
+	defer func() {
+		b.mu.Unlock()
+		notify()
+	}()
+
+	switch b.state {
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.threshold {
+			notify = b.setState(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if failed {
+			notify = b.setState(BreakerOpen)
+			return
+		}
+		b.inFlight--
+		b.successes++
+		if b.successes >= b.probes {
+			notify = b.setState(BreakerClosed)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package fyb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	var status int32 = http.StatusBadGateway
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte(`{"ask":"1","bid":"1","last":"1","vol":"1"}`))
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	breaker := NewBreaker(2, 10*time.Second)
+	breaker.now = func() time.Time { return now }
+	var changes []string
+	breaker.OnStateChange(func(from, to BreakerState) {
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+	api := New(ts.URL, "", "")
+	api.SetBreaker(breaker)
+
+	for i := 0; i < 2; i++ {
+		_, err := api.GetTicker()
+		require.EqualError(t, err, "502 Bad Gateway")
+	}
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	_, err := api.GetTicker()
+	require.IsType(t, &CircuitOpenError{}, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// After the cooldown a failing probe reopens the breaker.
+	now = now.Add(11 * time.Second)
+	require.Equal(t, BreakerHalfOpen, breaker.State())
+	_, err = api.GetTicker()
+	require.EqualError(t, err, "502 Bad Gateway")
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// A successful probe closes it.
+	now = now.Add(11 * time.Second)
+	atomic.StoreInt32(&status, http.StatusOK)
+	_, err = api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, BreakerClosed, breaker.State())
+
+	require.Equal(t, []string{
+		"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed",
+	}, changes)
+}
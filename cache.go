@@ -0,0 +1,144 @@
+package fyb
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache is a Middleware that caches public market data (ticker, order book
+// and trade history) for a short TTL. Concurrent calls for the same
+// resource share one upstream request, and when a refresh fails the last
+// good response is served for up to MaxStale. Entries too old to be served
+// are evicted, so polling ever-changing resources such as trades since the
+// last tid does not grow the cache. Streamed calls are passed through.
+//
+// Add it first so it sits outside other middleware:
+//
+//	cache := fyb.NewCache(500 * time.Millisecond)
+//	api.Use(cache.Middleware)
+type Cache struct {
+	ttl      time.Duration
+	maxStale time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	flights map[string]*flight
+	swept   time.Time
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	body    []byte
+	fetched time.Time
+}
+
+// flight is an upstream request shared by concurrent callers.
+type flight struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// NewCache returns a Cache keeping responses for ttl and serving stale
+// responses on errors for up to one minute.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		maxStale: time.Minute,
+		entries:  map[string]*cacheEntry{},
+		flights:  map[string]*flight{},
+		now:      time.Now,
+	}
+}
+
+// SetMaxStale sets how old a cached response may be when it is served
+// because a refresh failed. 0 disables serving stale responses.
+func (c *Cache) SetMaxStale(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxStale = d
+}
+
+// Invalidate drops all cached responses.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*cacheEntry{}
+}
+
+func cacheable(call *Call) bool {
+	if call.Method != "GET" || call.Auth || call.Stream {
+		return false
+	}
+	switch call.Endpoint {
+	case "tickerdetailed.json", "orderbook.json", "trades.json":
+		return true
+	}
+	return false
+}
+
+// Middleware implements the Middleware signature.
+func (c *Cache) Middleware(next Handler) Handler {
+	return func(call *Call) ([]byte, error) {
+		if !cacheable(call) {
+			return next(call)
+		}
+		key := call.Resource
+
+		c.mu.Lock()
+		entry := c.entries[key]
+		if entry != nil && c.now().Sub(entry.fetched) < c.ttl {
+			c.mu.Unlock()
+			return entry.body, nil
+		}
+		if f, ok := c.flights[key]; ok {
+			c.mu.Unlock()
+			<-f.done
+			return c.result(key, f)
+		}
+		f := &flight{done: make(chan struct{})}
+		c.flights[key] = f
+		c.mu.Unlock()
+
+		f.body, f.err = next(call)
+
+		c.mu.Lock()
+		if f.err == nil {
+			now := c.now()
+			c.entries[key] = &cacheEntry{body: f.body, fetched: now}
+			c.evict(now)
+		}
+		delete(c.flights, key)
+		c.mu.Unlock()
+		close(f.done)
+
+		return c.result(key, f)
+	}
+}
+
+// evict drops the entries too old to be served, at most once per ttl.
+// Called with c.mu held.
+func (c *Cache) evict(now time.Time) {
+	if now.Sub(c.swept) < c.ttl {
+		return
+	}
+	c.swept = now
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl+c.maxStale {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// result returns the outcome of f, falling back to a stale entry if f failed.
+func (c *Cache) result(key string, f *flight) ([]byte, error) {
+	if f.err == nil {
+		return f.body, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry := c.entries[key]; entry != nil && c.maxStale > 0 && c.now().Sub(entry.fetched) < c.ttl+c.maxStale {
+		return entry.body, nil
+	}
+	return f.body, f.err
+}
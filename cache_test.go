@@ -0,0 +1,84 @@
+package fyb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheCoalescesAndServesStale(t *testing.T) {
+	var calls int32
+	var failing atomic.Value
+	failing.Store(false)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if failing.Load().(bool) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"ask":"1012.48","bid":"1005.17","last":"1011.00","vol":"1.2"}`))
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	cache := NewCache(time.Second)
+	cache.now = func() time.Time { return now }
+	api := New(ts.URL, "", "")
+	api.Use(cache.Middleware)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker, err := api.GetTicker()
+			require.NoError(t, err)
+			require.Equal(t, "1012.48", ticker.Ask.String())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Expired and upstream failing: the stale ticker is served.
+	now = now.Add(2 * time.Second)
+	failing.Store(true)
+	ticker, err := api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, "1012.48", ticker.Ask.String())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Too old to serve.
+	now = now.Add(2 * time.Minute)
+	_, err = api.GetTicker()
+	require.Error(t, err)
+}
+
+func TestCacheEvictsOldEntries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	cache := NewCache(time.Second)
+	cache.SetMaxStale(10 * time.Second)
+	cache.now = func() time.Time { return now }
+	api := New(ts.URL, "", "")
+	api.Use(cache.Middleware)
+
+	// A poller advancing since creates a new entry every call.
+	for since := int64(1); since <= 30; since++ {
+		_, err := api.GetTradeHistory(since)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	require.True(t, len(cache.entries) <= 12, "%d entries", len(cache.entries))
+}
@@ -1,13 +1,15 @@
 package fyb
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -24,24 +26,27 @@ type Client struct {
 	httpTimeout time.Duration
 	debug       bool
 	apiBaseUrl  string
+	logger      Logger
+	metrics     Metrics
+	middleware  []Middleware
+	breaker     *Breaker
+	maxBody     int64
 }
 
 var (
 	// Technically 6 req/s allowed, but we're being nice / playing it safe.
 	reqInterval = 200 * time.Millisecond
+
+	errTimeout       = errors.New("timeout on reading data from FYB API")
+	errNoCredentials = errors.New("You need to set API Key and API Secret to call this method")
+	errBodyTooLarge  = errors.New("FYB API response body exceeds the maximum size")
+
+	defaultMaxBody int64 = 8 << 20
 )
 
 // NewClient return a new FYB HTTP client
 func NewClient(apiBaseUrl string, apiKey, apiSecret string) *Client {
-	return &Client{
-		apiKey:      apiKey,
-		apiSecret:   apiSecret,
-		httpClient:  &http.Client{},
-		throttle:    time.Tick(reqInterval),
-		httpTimeout: 30 * time.Second,
-		debug:       false,
-		apiBaseUrl:  apiBaseUrl,
-	}
+	return NewClientWithCustomTimeout(apiBaseUrl, apiKey, apiSecret, 30*time.Second)
 }
 
 // NewClientWithCustomTimeout returns a new FYB HTTP client with custom timeout
@@ -49,68 +54,104 @@ func NewClientWithCustomTimeout(apiBaseUrl string, apiKey, apiSecret string, tim
 	return &Client{
 		apiKey:      apiKey,
 		apiSecret:   apiSecret,
-		httpClient:  &http.Client{},
+		httpClient:  &http.Client{Transport: NewTransport(timeout)},
 		throttle:    time.Tick(reqInterval),
 		httpTimeout: timeout,
 		debug:       false,
 		apiBaseUrl:  apiBaseUrl,
+		logger:      nopLogger{},
+		metrics:     nopMetrics{},
+		maxBody:     defaultMaxBody,
 	}
 }
 
+// NewTransport returns the http.Transport used by NewClient: it keeps idle
+// connections to FYB open for reuse, attempts HTTP/2 and bounds the time
+// spent dialing, in the TLS handshake and waiting for response headers.
+// Use it as the next transport when wrapping with a RecordingTransport.
+func NewTransport(timeout time.Duration) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          32,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// SetLogger sets the logger used for request logging and debug dumps.
+// A nil logger discards all output.
+func (c *Client) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = nopLogger{}
+	}
+	c.logger = logger
+}
+
+// SetDebug enables dumping of every request and response at debug level.
+// The key and sig headers and the account email are redacted.
+func (c *Client) SetDebug(debug bool) {
+	c.debug = debug
+}
+
+// SetTransport replaces the HTTP transport used for requests, e.g. with a
+// RecordingTransport or ReplayTransport.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// SetMaxBodySize sets the largest response body the client reads, in
+// bytes. Larger responses fail with an error. Default is 8 MiB.
+func (c *Client) SetMaxBodySize(n int64) {
+	c.maxBody = n
+}
+
+// SetBreaker installs a circuit breaker checked before every request.
+// nil removes it.
+func (c *Client) SetBreaker(breaker *Breaker) {
+	c.breaker = breaker
+}
+
+// SetMetrics sets the collector notified about every API call.
+// A nil collector disables metrics.
+func (c *Client) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	c.metrics = metrics
+}
+
 func (c Client) dumpRequest(r *http.Request) {
 	if r == nil {
-		log.Print("dumpReq ok: <nil>")
+		c.logger.Debug("dump request", "dump", "<nil>")
 		return
 	}
 	dump, err := httputil.DumpRequest(r, true)
 	if err != nil {
-		log.Print("dumpReq err:", err)
+		c.logger.Debug("dump request", "error", err)
 	} else {
-		log.Print("dumpReq ok:", string(dump))
+		c.logger.Debug("dump request", "dump", redactDump(dump))
 	}
 }
 
-func (c Client) dumpResponse(r *http.Response) {
+// dumpResponse logs r with body, the part of its body read by makeReq.
+func (c Client) dumpResponse(r *http.Response, body []byte) {
 	if r == nil {
-		log.Print("dumpResponse ok: <nil>")
+		c.logger.Debug("dump response", "dump", "<nil>")
 		return
 	}
-	dump, err := httputil.DumpResponse(r, true)
+	dump, err := httputil.DumpResponse(r, false)
 	if err != nil {
-		log.Print("dumpResponse err:", err)
+		c.logger.Debug("dump response", "error", err)
 	} else {
-		log.Print("dumpResponse ok:", string(dump))
-	}
-}
-
-// doTimeoutRequest do a HTTP request with timeout
-func (c *Client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
-	}
-	done := make(chan result, 1)
-	go func() {
-
-		if c.debug {
-			c.dumpRequest(req)
-		}
-
-		resp, err := c.httpClient.Do(req)
-
-		if c.debug {
-			c.dumpResponse(resp)
-		}
-
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		return nil, errors.New("timeout on reading data from FYB API")
+		c.logger.Debug("dump response", "dump", redactDump(append(dump, body...)))
 	}
 }
 
@@ -120,45 +161,43 @@ func generateHmacSha1(text, key string) string {
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-func (c *Client) makeReq(method, resource string, payload map[string]string, authNeeded bool, respCh chan<- []byte, errCh chan<- error) {
-	body := []byte{}
-	connectTimer := time.NewTimer(c.httpTimeout)
+// apiResponse is the outcome of a single request made by makeReq.
+type apiResponse struct {
+	body   []byte
+	status int
+	err    error
+}
 
+// newRequest builds the signed HTTP request for call.
+func (c *Client) newRequest(ctx context.Context, call *Call) (*http.Request, error) {
 	var rawurl string
-	if strings.HasPrefix(resource, "http") {
-		rawurl = resource
+	if strings.HasPrefix(call.Resource, "http") {
+		rawurl = call.Resource
 	} else {
-		rawurl = fmt.Sprintf("%s/%s", c.apiBaseUrl, resource)
+		rawurl = fmt.Sprintf("%s/%s", c.apiBaseUrl, call.Resource)
 	}
-	//log.Printf("rawurl:%s", rawurl)
-	//log.Printf("authNeeded:%v", authNeeded)
-	//log.Printf("payload:%v", payload)
-	//log.Printf("method:%v", method)
 
 	formValues := url.Values{}
-	if authNeeded {
+	if call.Auth {
 		formValues.Add("timestamp", fmt.Sprintf("%d", time.Now().Unix()))
 	}
-	//log.Printf("payload:%v", payload)
-	for key, value := range payload {
+	for key, value := range call.Payload {
 		formValues.Set(key, value)
-		//formValues.Add(key, value)
 	}
 	formData := formValues.Encode()
-	//log.Printf("formData:%s", formData)
-	req, err := http.NewRequest(method, rawurl, strings.NewReader(formData))
+	req, err := http.NewRequestWithContext(ctx, call.Method, rawurl, strings.NewReader(formData))
 	if err != nil {
-		respCh <- body
-		//errCh <- errors.New("You need to set API Key and API Secret to call this method")
-		errCh <- err
-		return
+		return nil, err
+	}
+	for key, values := range call.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
 	}
 
-	if authNeeded {
+	if call.Auth {
 		if len(c.apiKey) == 0 || len(c.apiSecret) == 0 {
-			respCh <- body
-			errCh <- errors.New("You need to set API Key and API Secret to call this method")
-			return
+			return nil, errNoCredentials
 		}
 
 		sig := generateHmacSha1(formData, c.apiSecret)
@@ -166,50 +205,157 @@ func (c *Client) makeReq(method, resource string, payload map[string]string, aut
 		req.Header.Add("sig", sig)
 	}
 
-	if method == "POST" || method == "PUT" {
+	if call.Method == "POST" || call.Method == "PUT" {
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	}
 
 	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+// makeReq performs call with a deadline of httpTimeout. The response body
+// is read to the end so the connection can be reused, unless it is larger
+// than maxBody, in which case the connection is closed instead.
+func (c *Client) makeReq(call *Call) apiResponse {
+	body := []byte{}
+	ctx, cancel := context.WithTimeout(call.Context, c.httpTimeout)
+	defer cancel()
+	// timedOut maps our deadline and transport timeouts to errTimeout but
+	// leaves a cancelled call.Context alone.
+	timedOut := func(err error) error {
+		if call.Context.Err() != nil {
+			return err
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() || ctx.Err() == context.DeadlineExceeded {
+			return errTimeout
+		}
+		return err
+	}
 
+	req, err := c.newRequest(ctx, call)
 	if err != nil {
-		respCh <- body
-		errCh <- err
-		return
+		return apiResponse{body, 0, err}
 	}
 
+	if c.debug {
+		c.dumpRequest(req)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return apiResponse{body, 0, timedOut(err)}
+	}
 	defer resp.Body.Close()
 
-	body, err = ioutil.ReadAll(resp.Body)
+	body, err = ioutil.ReadAll(io.LimitReader(resp.Body, c.maxBody+1))
+	if c.debug {
+		c.dumpResponse(resp, body)
+	}
 	if err != nil {
-		respCh <- body
-		errCh <- err
-		return
+		return apiResponse{body, resp.StatusCode, timedOut(err)}
+	}
+	if int64(len(body)) > c.maxBody {
+		return apiResponse{nil, resp.StatusCode, errBodyTooLarge}
 	}
-	//log.Printf("body:%s", string(body))
 	if resp.StatusCode != 200 {
-		respCh <- body
-		errCh <- errors.New(resp.Status)
-		return
+		return apiResponse{body, resp.StatusCode, errors.New(resp.Status)}
 	}
 
-	respCh <- body
-	errCh <- nil
-	close(respCh)
-	close(errCh)
+	return apiResponse{body, resp.StatusCode, nil}
+}
+
+func newCall(method, resource string, payload map[string]string, authNeeded bool) *Call {
+	return &Call{
+		Context:  context.Background(),
+		Method:   method,
+		Resource: resource,
+		Endpoint: endpointName(resource),
+		Payload:  payload,
+		Auth:     authNeeded,
+		Ticket:   payload["orderNo"],
+		Header:   http.Header{},
+	}
 }
 
 // do prepare and process HTTP request to FYB API
 func (c *Client) do(method, resource string, payload map[string]string, authNeeded bool) (response []byte, err error) {
+	call := newCall(method, resource, payload, authNeeded)
+	return c.handler()(call)
+}
+
+// handler returns send wrapped in the middleware chain.
+func (c *Client) handler() Handler {
+	h := c.send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
+}
 
-	respCh := make(chan []byte)
-	errCh := make(chan error)
+// send is the innermost Handler: it performs call unless the breaker is
+// open. A streamed call leaves its body in call.StreamBody.
+func (c *Client) send(call *Call) (response []byte, err error) {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	var class string
+	if call.Stream {
+		call.StreamBody, class, err = c.openStream(call)
+	} else {
+		response, class, err = c.doOnce(call)
+	}
+	if c.breaker != nil {
+		c.breaker.record(class)
+	}
+	return response, err
+}
+
+// doOnce performs a single throttled request and reports it to the logger
+// and metrics collector.
+func (c *Client) doOnce(call *Call) ([]byte, string, error) {
+	waitStart := time.Now()
 	<-c.throttle
-	go c.makeReq(method, resource, payload, authNeeded, respCh, errCh)
-	response = <-respCh
-	err = <-errCh
-	return
+	c.metrics.ObserveThrottleWait(call.Endpoint, time.Since(waitStart))
+
+	start := time.Now()
+	res := c.makeReq(call)
+	latency := time.Since(start)
+	class := errorClass(res)
+	c.metrics.ObserveRequest(call.Endpoint, latency, class)
+	c.logCall(call, res, latency)
+	return res.body, class, res.err
+}
+
+// logCall logs the outcome of a request.
+func (c *Client) logCall(call *Call, res apiResponse, latency time.Duration) {
+	fields := []interface{}{
+		"endpoint", call.Endpoint,
+		"method", call.Method,
+		"status", res.status,
+		"latency", latency,
+	}
+	if call.Ticket != "" {
+		fields = append(fields, "ticket", call.Ticket)
+	}
+	if res.err != nil {
+		c.logger.Warn("fyb request failed", append(fields, "error", res.err)...)
+	} else {
+		c.logger.Debug("fyb request", fields...)
+	}
+}
+
+// endpointName strips the base URL and query string from resource,
+// e.g. "trades.json?since=1" becomes "trades.json".
+func endpointName(resource string) string {
+	if i := strings.Index(resource, "?"); i >= 0 {
+		resource = resource[:i]
+	}
+	if strings.HasPrefix(resource, "http") {
+		if i := strings.LastIndex(resource, "/"); i >= 0 {
+			resource = resource[i+1:]
+		}
+	}
+	return resource
 }
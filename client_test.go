@@ -0,0 +1,149 @@
+package fyb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionReuse(t *testing.T) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`upstream error`))
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	api := New(ts.URL, "", "")
+	for i := 0; i < 3; i++ {
+		_, err := api.GetTicker()
+		require.Error(t, err)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&conns))
+}
+
+func TestRequestDeadline(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	c := NewClientWithCustomTimeout(ts.URL, "", "", 50*time.Millisecond)
+	res := c.makeReq(&Call{Context: context.Background(), Method: "GET", Resource: "tickerdetailed.json"})
+	require.Equal(t, errTimeout, res.err)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ask":"1.5","bid":"1.4","last":"1.45","vol":"10"}`))
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "")
+	api.SetMaxBodySize(16)
+	_, err := api.GetTicker()
+	require.Equal(t, errBodyTooLarge, err)
+
+	api.SetMaxBodySize(1024)
+	ticker, err := api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, "1.5", ticker.Ask.String())
+}
+
+func TestWithdrawPayload(t *testing.T) {
+	var form map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		w.Write([]byte(`{"error":0,"msg":"11750"}`))
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "key", "secret")
+	res, err := api.Withdraw(0.5, " 1FkrHkVAFg5Jn3s2njdnWFcbizMYbb423W\n", "btc")
+	require.NoError(t, err)
+	require.Equal(t, "11750", res.Msg)
+	require.Equal(t, "1FkrHkVAFg5Jn3s2njdnWFcbizMYbb423W", form["destination"])
+	require.Equal(t, "0.500000", form["amount"])
+	require.Equal(t, "BTC", form["type"])
+	require.NotEmpty(t, form["timestamp"])
+}
+
+func TestFiatBalanceByMarket(t *testing.T) {
+	info := AccountInfoResponse{SgdBal: decimal.NewFromInt(57), SekBal: decimal.NewFromInt(410)}
+	require.Equal(t, "SGD", New(APIBaseURLForSGD, "", "").Currency())
+	require.Equal(t, "57", info.FiatBal("SGD").String())
+	sek := New(APIBaseURLForSEK, "", "")
+	require.Equal(t, "SEK", sek.Currency())
+	require.Equal(t, "410", info.FiatBal(sek.Currency()).String())
+}
+
+// dumpLogger records the debug dumps.
+type dumpLogger struct {
+	nopLogger
+	dumps []string
+}
+
+func (l *dumpLogger) Debug(msg string, args ...interface{}) {
+	if msg == "dump response" && len(args) == 2 {
+		l.dumps = append(l.dumps, fmt.Sprint(args[1]))
+	}
+}
+
+func TestMaxBodySizeWithDebug(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An endless body.
+		chunk := []byte(strings.Repeat("x", 1024))
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	logger := &dumpLogger{}
+	c := NewClientWithCustomTimeout(ts.URL, "", "", 5*time.Second)
+	c.SetDebug(true)
+	c.SetLogger(logger)
+	c.SetMaxBodySize(16)
+	res := c.makeReq(&Call{Context: context.Background(), Method: "GET", Resource: "tickerdetailed.json"})
+	require.Equal(t, errBodyTooLarge, res.err)
+	require.Len(t, logger.dumps, 1)
+	require.True(t, strings.HasSuffix(logger.dumps[0], strings.Repeat("x", 17)))
+	require.True(t, len(logger.dumps[0]) < 1024, "only the limited body is dumped")
+}
+
+func TestErrorBodyRedacted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accNo":"x","email":"me@example.com","error":0}`))
+	}))
+	defer ts.Close()
+
+	_, err := New(ts.URL, "key", "secret").GetAccountInfo()
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "me@example.com")
+}
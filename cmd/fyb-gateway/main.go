@@ -0,0 +1,90 @@
+// Command fyb-gateway serves the gateway package's REST API in front of a
+// single FYB account.
+//
+// Usage:
+//
+//	FYB_KEY=... FYB_SECRET=... fyb-gateway -config callers.json [-listen 127.0.0.1:8080] [-market SGD] [-metrics-listen 127.0.0.1:9100]
+//
+// callers.json lists the callers allowed to use the gateway:
+//
+//	{"callers": [
+//	  {"name": "research", "token": "...", "scopes": ["read"]},
+//	  {"name": "risk-bot", "token": "...", "scopes": ["read", "trade"]}
+//	]}
+//
+// Prometheus metrics are served on /metrics of a separate, unauthenticated
+// listener, off unless -metrics-listen is set.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/gateway"
+)
+
+var markets = map[string]string{
+	"SGD":  fyb.APIBaseURLForSGD,
+	"SEK":  fyb.APIBaseURLForSEK,
+	"TEST": fyb.APIBaseURLForTest,
+}
+
+type config struct {
+	Callers []gateway.Caller `json:"callers"`
+}
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8080", "address to listen on")
+	market := flag.String("market", "SGD", "market: SGD, SEK or TEST")
+	configPath := flag.String("config", "callers.json", "callers configuration file")
+	metricsListen := flag.String("metrics-listen", "", "address to serve Prometheus metrics on /metrics; empty disables them")
+	flag.Parse()
+
+	url, ok := markets[strings.ToUpper(*market)]
+	if !ok {
+		log.Fatalf("unknown market %q", *market)
+	}
+	data, err := ioutil.ReadFile(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+	if len(cfg.Callers) == 0 {
+		log.Fatalf("%s: no callers configured", *configPath)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	api := fyb.New(url, os.Getenv("FYB_KEY"), os.Getenv("FYB_SECRET"))
+	api.SetLogger(logger)
+
+	gw, err := gateway.New(api, cfg.Callers)
+	if err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+	gw.SetLogger(logger)
+
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", gw)
+	if *metricsListen != "" {
+		m := fyb.NewPrometheusMetrics()
+		api.SetMetrics(m)
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", m)
+		logger.Info("serving metrics", "addr", *metricsListen)
+		go func() {
+			log.Fatal(http.ListenAndServe(*metricsListen, metricsMux))
+		}()
+	}
+	logger.Info("listening", "addr", *listen, "market", strings.ToUpper(*market), "callers", len(cfg.Callers))
+	log.Fatal(http.ListenAndServe(*listen, mux))
+}
@@ -0,0 +1,91 @@
+// Command fyb-grpc serves the FYB API over gRPC (see fybgrpc/fybpb/fyb.proto).
+//
+// Usage:
+//
+//	FYB_KEY=... FYB_SECRET=... fyb-grpc -config callers.json [-listen 127.0.0.1:9090] [-market SGD] [-interval 2s] [-trade] [-withdraw]
+//
+// Callers authenticate with "authorization: Bearer <token>" metadata and
+// are configured as for fyb-gateway, with read, trade and withdraw scopes.
+// PlaceOrder, CancelOrder and Withdraw are refused unless -trade or
+// -withdraw is given as well.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/fybgrpc"
+	"github.com/rakd/go-fyb/fybgrpc/fybpb"
+	"github.com/rakd/go-fyb/gateway"
+	"google.golang.org/grpc"
+)
+
+var markets = map[string]string{
+	"SGD":  fyb.APIBaseURLForSGD,
+	"SEK":  fyb.APIBaseURLForSEK,
+	"TEST": fyb.APIBaseURLForTest,
+}
+
+type config struct {
+	Callers []gateway.Caller `json:"callers"`
+}
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:9090", "address to listen on")
+	configPath := flag.String("config", "callers.json", "callers configuration file")
+	market := flag.String("market", "SGD", "market: SGD, SEK or TEST")
+	interval := flag.Duration("interval", 2*time.Second, "poll interval for streaming RPCs")
+	trade := flag.Bool("trade", false, "allow PlaceOrder and CancelOrder")
+	withdraw := flag.Bool("withdraw", false, "allow Withdraw")
+	flag.Parse()
+
+	url, ok := markets[strings.ToUpper(*market)]
+	if !ok {
+		log.Fatalf("unknown market %q", *market)
+	}
+	data, err := ioutil.ReadFile(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+	if len(cfg.Callers) == 0 {
+		log.Fatalf("%s: no callers configured", *configPath)
+	}
+	auth, err := fybgrpc.Auth(cfg.Callers)
+	if err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	api := fyb.New(url, os.Getenv("FYB_KEY"), os.Getenv("FYB_SECRET"))
+	api.SetLogger(logger)
+
+	tracker := fyb.NewTracker(api, *interval, 0)
+	tracker.OnError = func(err error) { logger.Warn("tracker poll failed", "error", err) }
+	tracker.Start()
+	defer tracker.Stop()
+
+	srv := fybgrpc.NewServer(api, tracker)
+	srv.AllowTrading(*trade)
+	srv.AllowWithdrawals(*withdraw)
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gs := grpc.NewServer(auth...)
+	fybpb.RegisterFYBServer(gs, srv)
+	logger.Info("listening", "addr", *listen, "market", strings.ToUpper(*market), "trade", *trade, "withdraw", *withdraw)
+	log.Fatal(gs.Serve(lis))
+}
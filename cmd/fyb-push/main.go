@@ -0,0 +1,54 @@
+// Command fyb-push polls FYB once and pushes order book and trade updates
+// to WebSocket subscribers on /ws (see package push for the protocol).
+//
+// Usage:
+//
+//	fyb-push [-listen 127.0.0.1:8081] [-market SGD] [-interval 2s]
+package main
+
+import (
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/push"
+)
+
+var markets = map[string]string{
+	"SGD":  fyb.APIBaseURLForSGD,
+	"SEK":  fyb.APIBaseURLForSEK,
+	"TEST": fyb.APIBaseURLForTest,
+}
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8081", "address to listen on")
+	market := flag.String("market", "SGD", "market: SGD, SEK or TEST")
+	interval := flag.Duration("interval", 2*time.Second, "FYB poll interval")
+	flag.Parse()
+
+	url, ok := markets[strings.ToUpper(*market)]
+	if !ok {
+		log.Fatalf("unknown market %q", *market)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	api := fyb.New(url, "", "")
+	api.SetLogger(logger)
+
+	tracker := fyb.NewTracker(api, *interval, 0)
+	tracker.OnError = func(err error) { logger.Warn("tracker poll failed", "error", err) }
+	srv := push.NewServer(tracker)
+	srv.Start()
+	tracker.Start()
+	defer tracker.Stop()
+	defer srv.Stop()
+
+	http.Handle("/ws", srv)
+	logger.Info("listening", "addr", *listen, "market", strings.ToUpper(*market))
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+)
+
+const maxTrades = 50
+
+// Keys produced by readKeys besides plain characters.
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyEnter = "enter"
+	keyBack  = "backspace"
+	keyEsc   = "esc"
+)
+
+// state is everything the view needs to draw one frame.
+type state struct {
+	market     string
+	private    bool
+	ticker     fyb.Ticker
+	book       fyb.OrderBook
+	trades     fyb.Trades
+	orders     fyb.PendingOrderResponse
+	account    fyb.AccountInfoResponse
+	selected   int
+	updated    time.Time
+	status     string
+	prompt     string // input line label, empty when not editing
+	input      string
+	confirming bool
+}
+
+type app struct {
+	api *fyb.Fyb
+
+	mu      sync.Mutex
+	st      state
+	lastTID int64
+	pending func() // action waiting for confirmation
+	submit  func(string)
+	dirty   chan struct{}
+	refresh chan struct{}
+}
+
+func newApp(api *fyb.Fyb, private bool, market string) *app {
+	return &app{
+		api:     api,
+		st:      state{market: market, private: private, status: "loading..."},
+		dirty:   make(chan struct{}, 1),
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+func (a *app) redraw() {
+	select {
+	case a.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (a *app) requestRefresh() {
+	select {
+	case a.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (a *app) setStatus(format string, args ...interface{}) {
+	a.mu.Lock()
+	a.st.status = fmt.Sprintf(format, args...)
+	a.mu.Unlock()
+	a.redraw()
+}
+
+// poll fetches all panels once. Panels whose request fails keep their
+// previous contents.
+func (a *app) poll() {
+	var errs []string
+
+	ticker, tickerErr := a.api.GetTicker()
+	if tickerErr != nil {
+		errs = append(errs, "ticker: "+tickerErr.Error())
+	}
+	book, bookErr := a.api.GetOrderBook()
+	if bookErr != nil {
+		errs = append(errs, "book: "+bookErr.Error())
+	}
+	a.mu.Lock()
+	since := a.lastTID
+	a.mu.Unlock()
+	trades, err := a.api.GetTradeHistory(since)
+	if err != nil {
+		errs = append(errs, "trades: "+err.Error())
+	}
+
+	var orders fyb.PendingOrderResponse
+	var account fyb.AccountInfoResponse
+	var ordersErr, accountErr error
+	if a.st.private {
+		if orders, ordersErr = a.api.GetPendingOrders(); ordersErr != nil {
+			errs = append(errs, "orders: "+ordersErr.Error())
+		}
+		if account, accountErr = a.api.GetAccountInfo(); accountErr != nil {
+			errs = append(errs, "account: "+accountErr.Error())
+		}
+	}
+
+	a.mu.Lock()
+	if tickerErr == nil {
+		a.st.ticker = ticker
+	}
+	if bookErr == nil {
+		a.st.book = book
+	}
+	for _, t := range trades {
+		if t.TID > a.lastTID {
+			a.st.trades = append(a.st.trades, t)
+			a.lastTID = t.TID
+		}
+	}
+	if len(a.st.trades) > maxTrades {
+		a.st.trades = a.st.trades[len(a.st.trades)-maxTrades:]
+	}
+	if a.st.private && ordersErr == nil {
+		a.st.orders = orders
+		if a.st.selected >= len(orders.Orders) {
+			a.st.selected = len(orders.Orders) - 1
+		}
+		if a.st.selected < 0 {
+			a.st.selected = 0
+		}
+	}
+	if a.st.private && accountErr == nil {
+		a.st.account = account
+	}
+	a.st.updated = time.Now()
+	if len(errs) > 0 {
+		a.st.status = strings.Join(errs, "; ")
+	} else if a.st.status == "loading..." {
+		a.st.status = ""
+	}
+	a.mu.Unlock()
+	a.redraw()
+}
+
+func (a *app) pollLoop(interval time.Duration, quit <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		a.poll()
+		select {
+		case <-quit:
+			return
+		case <-t.C:
+		case <-a.refresh:
+		}
+	}
+}
+
+// run draws frames and handles keys until the user quits.
+func (a *app) run(interval time.Duration, in io.Reader, out io.Writer, size func() (int, int)) {
+	quit := make(chan struct{})
+	defer close(quit)
+	keys := make(chan string)
+	go readKeys(in, keys)
+	go a.pollLoop(interval, quit)
+
+	fmt.Fprint(out, hideCursor)
+	a.redraw()
+	for {
+		select {
+		case <-a.dirty:
+			w, h := size()
+			a.mu.Lock()
+			frame := render(a.st, w, h)
+			a.mu.Unlock()
+			fmt.Fprint(out, frame)
+		case key, ok := <-keys:
+			if !ok || !a.handleKey(key) {
+				return
+			}
+			a.redraw()
+		}
+	}
+}
+
+// handleKey applies a key press and reports whether to keep running.
+func (a *app) handleKey(key string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	st := &a.st
+
+	if st.confirming {
+		action := a.pending
+		st.confirming, a.pending = false, nil
+		if key == "y" || key == "Y" {
+			st.status = "sending..."
+			go action()
+		} else {
+			st.status = "cancelled"
+		}
+		return true
+	}
+
+	if st.prompt != "" {
+		switch key {
+		case keyEnter:
+			input, submit := st.input, a.submit
+			st.prompt, st.input, a.submit = "", "", nil
+			submit(input)
+		case keyEsc:
+			st.prompt, st.input, a.submit = "", "", nil
+		case keyBack:
+			if len(st.input) > 0 {
+				st.input = st.input[:len(st.input)-1]
+			}
+		default:
+			if len(key) == 1 {
+				st.input += key
+			}
+		}
+		return true
+	}
+
+	switch key {
+	case "q", "\x03":
+		return false
+	case "r":
+		a.requestRefresh()
+	case keyUp, "k":
+		if st.selected > 0 {
+			st.selected--
+		}
+	case keyDown, "j":
+		if st.selected < len(st.orders.Orders)-1 {
+			st.selected++
+		}
+	case "b", "s":
+		if !st.private {
+			st.status = "set FYB_KEY and FYB_SECRET to trade"
+			break
+		}
+		side := map[string]string{"b": "BUY", "s": "SELL"}[key]
+		st.prompt = side + " PRICE QTY: "
+		a.submit = func(input string) { a.askOrder(side, input) }
+	case "c":
+		if len(st.orders.Orders) == 0 {
+			break
+		}
+		ticket := st.orders.Orders[st.selected].Ticket
+		st.status = fmt.Sprintf("cancel order %d? (y/n)", ticket)
+		st.confirming = true
+		a.pending = func() { a.cancelOrder(ticket) }
+	}
+	return true
+}
+
+// askOrder parses "PRICE QTY" and asks for confirmation. Called with a.mu held.
+func (a *app) askOrder(side, input string) {
+	fields := strings.Fields(input)
+	if len(fields) != 2 {
+		a.st.status = "expected PRICE QTY"
+		return
+	}
+	price, err1 := strconv.ParseFloat(fields[0], 64)
+	qty, err2 := strconv.ParseFloat(fields[1], 64)
+	if err1 != nil || err2 != nil || price <= 0 || qty <= 0 {
+		a.st.status = "bad PRICE QTY: " + input
+		return
+	}
+	a.st.status = fmt.Sprintf("%s %v BTC at %v? (y/n)", side, qty, price)
+	a.st.confirming = true
+	a.pending = func() { a.placeOrder(side, price, qty) }
+}
+
+func (a *app) placeOrder(side string, price, qty float64) {
+	res, err := a.api.PlaceOrder(side, price, qty)
+	switch {
+	case err != nil:
+		a.setStatus("%s failed: %v", side, err)
+	case res.Error != 0:
+		a.setStatus("%s failed: %s", side, res.Msg)
+	default:
+		a.setStatus("placed order %s", res.PendingOID)
+	}
+	a.requestRefresh()
+}
+
+func (a *app) cancelOrder(ticket int64) {
+	res, err := a.api.CancelPendingOrder(ticket)
+	switch {
+	case err != nil:
+		a.setStatus("cancel %d failed: %v", ticket, err)
+	case res.Error != 0:
+		a.setStatus("cancel %d failed: %s", ticket, res.Msg)
+	default:
+		a.setStatus("cancelled order %d", ticket)
+	}
+	a.requestRefresh()
+}
+
+// readKeys decodes raw terminal input into key names.
+func readKeys(in io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		b := buf[:n]
+		switch {
+		case string(b) == "\x1b[A":
+			keys <- keyUp
+		case string(b) == "\x1b[B":
+			keys <- keyDown
+		case string(b) == "\x1b":
+			keys <- keyEsc
+		default:
+			for _, c := range b {
+				switch c {
+				case '\r', '\n':
+					keys <- keyEnter
+				case 0x7f, 0x08:
+					keys <- keyBack
+				default:
+					keys <- string(c)
+				}
+			}
+		}
+	}
+}
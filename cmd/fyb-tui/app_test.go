@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tickerdetailed.json":
+			w.Write([]byte(`{"ask":"1012.48","bid":"1005.17","last":"1011.00","vol":"1.2"}`))
+		case "/orderbook.json":
+			w.Write([]byte(`{"asks":[[1012.48,0.5],[1020,1.25]],"bids":[[1005.17,0.3],[1000,2.5]]}`))
+		case "/trades.json":
+			w.Write([]byte(`[{"amount":"0.0100","date":1387975210,"price":"1011.00","tid":2218611}]`))
+		case "/getpendingorders":
+			w.Write([]byte(`{"error":0,"orders":[{"date":1387099682,"price":"5.00","qty":"0.99000000","ticket":6,"type":"S"}]}`))
+		case "/getaccinfo":
+			w.Write([]byte(`{"accNo":1234,"btcBal":"23.00000000","error":0,"sgdBal":"57.50"}`))
+		}
+	}))
+	defer ts.Close()
+
+	a := newApp(fyb.New(ts.URL, "key", "secret"), true, "SGD")
+	a.poll()
+	frame := render(a.st, 100, 30)
+	require.Contains(t, frame, "1012.48")
+	require.Contains(t, frame, "1005.17")
+	require.Contains(t, frame, "Account 1234")
+	require.Contains(t, frame, "0.01")
+	require.Equal(t, 30, len(strings.Split(frame, "\r\n")))
+	require.Equal(t, int64(2218611), a.lastTID)
+}
+
+func TestOrderEntry(t *testing.T) {
+	a := newApp(fyb.New("http://127.0.0.1:1", "key", "secret"), true, "SGD")
+	for _, k := range []string{"b", "1", "0", "0", "0", " ", "0", ".", "1", keyEnter} {
+		require.True(t, a.handleKey(k))
+	}
+	require.True(t, a.st.confirming)
+	require.Equal(t, "BUY 0.1 BTC at 1000? (y/n)", a.st.status)
+
+	require.True(t, a.handleKey("n"))
+	require.False(t, a.st.confirming)
+	require.Nil(t, a.pending)
+	require.False(t, a.handleKey("q"))
+}
@@ -0,0 +1,69 @@
+// Command fyb-tui is a terminal dashboard for FYB showing a live depth
+// ladder, recent trades, pending orders and balances.
+//
+// Usage:
+//
+//	fyb-tui [-market SGD|SEK|TEST] [-interval 3s]
+//
+// Keys:
+//
+//	b / s        buy / sell (asks for PRICE QTY, then confirmation)
+//	up / down    select a pending order (also k / j)
+//	c            cancel the selected order
+//	r            refresh now
+//	q            quit
+//
+// The account panels are shown when FYB_KEY and FYB_SECRET are set.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"golang.org/x/term"
+)
+
+var markets = map[string]string{
+	"SGD":  fyb.APIBaseURLForSGD,
+	"SEK":  fyb.APIBaseURLForSEK,
+	"TEST": fyb.APIBaseURLForTest,
+}
+
+func main() {
+	market := flag.String("market", "SGD", "market: SGD, SEK or TEST")
+	interval := flag.Duration("interval", 3*time.Second, "refresh interval")
+	flag.Parse()
+
+	url, ok := markets[strings.ToUpper(*market)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "fyb-tui: unknown market %q\n", *market)
+		os.Exit(2)
+	}
+	key, secret := os.Getenv("FYB_KEY"), os.Getenv("FYB_SECRET")
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Fprintln(os.Stderr, "fyb-tui: stdin is not a terminal")
+		os.Exit(1)
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fyb-tui:", err)
+		os.Exit(1)
+	}
+	defer term.Restore(fd, oldState)
+
+	a := newApp(fyb.New(url, key, secret), key != "" && secret != "", strings.ToUpper(*market))
+	a.run(*interval, os.Stdin, os.Stdout, func() (int, int) {
+		w, h, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return 80, 24
+		}
+		return w, h
+	})
+	fmt.Print(showCursor + clearScreen)
+}
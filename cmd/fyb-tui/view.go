@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	red         = "\x1b[31m"
+	green       = "\x1b[32m"
+	bold        = "\x1b[1m"
+	inverse     = "\x1b[7m"
+	reset       = "\x1b[0m"
+
+	ladderWidth = 40
+	maxOrders   = 8
+)
+
+// cell pads or truncates s to exactly width columns.
+func cell(s string, width int) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// colored is a line fragment with its visible width known up front, so
+// escape codes do not disturb the layout.
+type colored struct {
+	text  string
+	color string
+}
+
+func (c colored) String() string {
+	if c.color == "" {
+		return c.text
+	}
+	return c.color + c.text + reset
+}
+
+// render draws a full frame of w x h characters. Lines are separated by
+// "\r\n" because the terminal is in raw mode.
+func render(st state, w, h int) string {
+	var lines []string
+
+	header := fmt.Sprintf("FYB %s  last %s  bid %s  ask %s  vol %s",
+		st.market, st.ticker.Last, st.ticker.Bid, st.ticker.Ask, st.ticker.Vol)
+	if !st.updated.IsZero() {
+		header += "  updated " + st.updated.Format("15:04:05")
+	}
+	lines = append(lines, bold+cell(header, w)+reset, "")
+
+	// Bottom section: balances and pending orders, status, help.
+	var bottom []string
+	if st.private {
+		a := st.account
+		bottom = append(bottom, fmt.Sprintf("Account %d  BTC %s  %s %s  deposit %s",
+			a.AccNo, a.BtcBal, st.market, a.FiatBal(st.market), a.BtcDeposit), "")
+		bottom = append(bottom, cell("  TICKET  TYPE  PRICE         QTY", w))
+		orders := st.orders.Orders
+		if len(orders) == 0 {
+			bottom = append(bottom, "  (no pending orders)")
+		}
+		first := 0
+		if st.selected >= maxOrders {
+			first = st.selected - maxOrders + 1
+		}
+		for i := first; i < len(orders) && i < first+maxOrders; i++ {
+			o := orders[i]
+			line := cell(fmt.Sprintf("  %-6d  %-4s  %-12s  %s", o.Ticket, o.Type, o.Price, o.Qty), w)
+			if i == st.selected {
+				line = inverse + line + reset
+			}
+			bottom = append(bottom, line)
+		}
+	} else {
+		bottom = append(bottom, "Set FYB_KEY and FYB_SECRET to see balances and orders.")
+	}
+	bottom = append(bottom, "", cell(st.status, w))
+	if st.prompt != "" {
+		bottom = append(bottom, st.prompt+st.input+"_")
+	} else {
+		bottom = append(bottom, "b buy  s sell  ↑/↓ select  c cancel  r refresh  q quit")
+	}
+
+	// Middle section: depth ladder on the left, trades on the right.
+	mid := h - len(lines) - len(bottom)
+	if mid < 3 {
+		mid = 3
+	}
+	depth := (mid - 2) / 2
+	var ladder []colored
+	ladder = append(ladder, colored{cell(fmt.Sprintf("%12s %12s %12s", "BID QTY", "PRICE", "ASK QTY"), ladderWidth), ""})
+	asks := st.book.Asks
+	if len(asks) > depth {
+		asks = asks[:depth]
+	}
+	for i := depth - 1; i >= 0; i-- {
+		if i >= len(asks) {
+			ladder = append(ladder, colored{cell("", ladderWidth), ""})
+			continue
+		}
+		ladder = append(ladder, colored{cell(fmt.Sprintf("%12s %12s %12s", "", asks[i].Price, asks[i].Amount), ladderWidth), red})
+	}
+	ladder = append(ladder, colored{cell(fmt.Sprintf("%12s %12s", "", "-- "+st.ticker.Last.String()+" --"), ladderWidth), bold})
+	for i := 0; i < depth && i < len(st.book.Bids); i++ {
+		b := st.book.Bids[i]
+		ladder = append(ladder, colored{cell(fmt.Sprintf("%12s %12s", b.Amount, b.Price), ladderWidth), green})
+	}
+
+	var trades []string
+	trades = append(trades, fmt.Sprintf("%-9s %12s %12s", "TIME", "PRICE", "AMOUNT"))
+	for i := len(st.trades) - 1; i >= 0 && len(trades) < mid; i-- {
+		t := st.trades[i]
+		trades = append(trades, fmt.Sprintf("%-9s %12s %12s",
+			time.Unix(t.Date, 0).Format("15:04:05"), t.Price, t.Amount))
+	}
+
+	for i := 0; i < mid; i++ {
+		left := colored{cell("", ladderWidth), ""}
+		if i < len(ladder) {
+			left = ladder[i]
+		}
+		right := ""
+		if i < len(trades) {
+			right = trades[i]
+		}
+		lines = append(lines, left.String()+"  "+cell(right, w-ladderWidth-2))
+	}
+	lines = append(lines, bottom...)
+
+	return clearScreen + strings.Join(lines, "\r\n")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func (c *cli) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+}
+
+func formatDate(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04:05")
+}
+
+// apiError turns a non-zero error field of a private API response into an error.
+func apiError(code int64, msg string) error {
+	if code == 0 {
+		return nil
+	}
+	if msg == "" {
+		msg = fmt.Sprintf("error %d", code)
+	}
+	return errors.New(msg)
+}
+
+func (c *cli) ticker(args []string) error {
+	ticker, err := c.api.GetTicker()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(ticker)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "ASK\tBID\tLAST\tVOL")
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ticker.Ask, ticker.Bid, ticker.Last, ticker.Vol)
+	return w.Flush()
+}
+
+func (c *cli) book(args []string) error {
+	fs := flag.NewFlagSet("book", flag.ContinueOnError)
+	depth := fs.Int("depth", 10, "number of levels per side")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	book, err := c.api.GetOrderBook()
+	if err != nil {
+		return err
+	}
+	if *depth > 0 {
+		if len(book.Asks) > *depth {
+			book.Asks = book.Asks[:*depth]
+		}
+		if len(book.Bids) > *depth {
+			book.Bids = book.Bids[:*depth]
+		}
+	}
+	if c.json {
+		return c.printJSON(book)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "BID QTY\tBID\tASK\tASK QTY")
+	for i := 0; i < len(book.Asks) || i < len(book.Bids); i++ {
+		var bidQty, bid, ask, askQty string
+		if i < len(book.Bids) {
+			bidQty, bid = book.Bids[i].Amount.String(), book.Bids[i].Price.String()
+		}
+		if i < len(book.Asks) {
+			ask, askQty = book.Asks[i].Price.String(), book.Asks[i].Amount.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", bidQty, bid, ask, askQty)
+	}
+	return w.Flush()
+}
+
+func (c *cli) trades(args []string) error {
+	fs := flag.NewFlagSet("trades", flag.ContinueOnError)
+	since := fs.Int64("since", 0, "trade ID to start from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	trades, err := c.api.GetTradeHistory(*since)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(trades)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "TID\tDATE\tPRICE\tAMOUNT")
+	for _, t := range trades {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", t.TID, formatDate(t.Date), t.Price, t.Amount)
+	}
+	return w.Flush()
+}
+
+func (c *cli) balance(args []string) error {
+	info, err := c.api.GetAccountInfo()
+	if err != nil {
+		return err
+	}
+	if err := apiError(info.Error, info.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(info)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "ACCOUNT\tBTC\tFIAT\tBTC DEPOSIT")
+	fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", info.AccNo, info.BtcBal, info.FiatBal(c.api.Currency()), info.BtcDeposit)
+	return w.Flush()
+}
+
+func (c *cli) orders(args []string) error {
+	res, err := c.api.GetPendingOrders()
+	if err != nil {
+		return err
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(res.Orders)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "TICKET\tTYPE\tPRICE\tQTY\tDATE")
+	for _, o := range res.Orders {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", o.Ticket, o.Type, o.Price, o.Qty, formatDate(o.Date))
+	}
+	return w.Flush()
+}
+
+func (c *cli) history(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	limit := fs.Int64("limit", 20, "number of orders to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	res, err := c.api.GetOrderHistory(*limit)
+	if err != nil {
+		return err
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(res.Orders)
+	}
+	w := c.table()
+	fmt.Fprintln(w, "TICKET\tCREATED\tEXECUTED\tPRICE\tQTY")
+	for _, o := range res.Orders {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", o.Ticket, formatDate(o.DateCreated), formatDate(o.DateExecuted), o.Price, o.Qty)
+	}
+	return w.Flush()
+}
+
+func (c *cli) place(side string, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: fyb %s PRICE QTY", side)
+	}
+	price, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("bad price: %v", err)
+	}
+	qty, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("bad qty: %v", err)
+	}
+	if !c.ask(fmt.Sprintf("Place %s order for %f BTC at %f?", side, qty, price)) {
+		return errors.New("aborted")
+	}
+	res, err := c.api.PlaceOrder(side, price, qty)
+	if err != nil {
+		return err
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(res)
+	}
+	fmt.Fprintf(c.stdout, "placed order %s\n", res.PendingOID)
+	return nil
+}
+
+func (c *cli) cancel(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: fyb cancel TICKET")
+	}
+	ticket, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad ticket: %v", err)
+	}
+	if !c.ask(fmt.Sprintf("Cancel order %d?", ticket)) {
+		return errors.New("aborted")
+	}
+	res, err := c.api.CancelPendingOrder(ticket)
+	if err != nil {
+		return err
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(res)
+	}
+	fmt.Fprintf(c.stdout, "cancelled order %d\n", ticket)
+	return nil
+}
+
+func (c *cli) withdraw(args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errors.New("usage: fyb withdraw AMOUNT BTC|XFERS [ADDRESS]")
+	}
+	amount, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("bad amount: %v", err)
+	}
+	kind := strings.ToUpper(args[1])
+	var destination string
+	if len(args) == 3 {
+		destination = args[2]
+	}
+	if kind == "BTC" && destination == "" {
+		return errors.New("BTC withdrawals need an address")
+	}
+	prompt := fmt.Sprintf("Withdraw %f via %s?", amount, kind)
+	if destination != "" {
+		prompt = fmt.Sprintf("Withdraw %f %s to %s?", amount, kind, destination)
+	}
+	if !c.ask(prompt) {
+		return errors.New("aborted")
+	}
+	res, err := c.api.Withdraw(amount, destination, kind)
+	if err != nil {
+		return err
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(res)
+	}
+	fmt.Fprintf(c.stdout, "withdrawal submitted: %s\n", res.Msg)
+	return nil
+}
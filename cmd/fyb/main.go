@@ -0,0 +1,133 @@
+// Command fyb is a command-line client for the fybsg.com and fybse.se APIs.
+//
+// Usage:
+//
+//	fyb [-market SGD|SEK|TEST] [-json] [-y] <command> [args]
+//
+// Commands:
+//
+//	ticker                          show the detailed ticker
+//	book [-depth N]                 show the order book
+//	trades [-since TID]             show public trades
+//	balance                         show account balances
+//	orders                          show pending orders
+//	history [-limit N]              show order history
+//	buy PRICE QTY                   place a buy order
+//	sell PRICE QTY                  place a sell order
+//	cancel TICKET                   cancel a pending order
+//	withdraw AMOUNT TYPE [ADDRESS]  withdraw BTC or XFERS
+//
+// Private commands read the API key and secret from FYB_KEY and FYB_SECRET.
+// Commands that change the account ask for confirmation unless -y is given.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	fyb "github.com/rakd/go-fyb"
+)
+
+var markets = map[string]string{
+	"SGD":  fyb.APIBaseURLForSGD,
+	"SEK":  fyb.APIBaseURLForSEK,
+	"TEST": fyb.APIBaseURLForTest,
+}
+
+// cli holds the global options shared by all commands.
+type cli struct {
+	api    *fyb.Fyb
+	json   bool
+	yes    bool
+	stdin  *bufio.Reader
+	stdout io.Writer
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "fyb:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("fyb", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	market := fs.String("market", "SGD", "market: SGD, SEK or TEST")
+	baseURL := fs.String("url", "", "API base URL, overrides -market")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	yes := fs.Bool("y", false, "do not ask for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: fyb [flags] ticker|book|trades|balance|orders|history|buy|sell|cancel|withdraw [args]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	url := *baseURL
+	if url == "" {
+		var ok bool
+		if url, ok = markets[strings.ToUpper(*market)]; !ok {
+			return fmt.Errorf("unknown market %q", *market)
+		}
+	}
+
+	c := &cli{
+		api:    fyb.New(url, os.Getenv("FYB_KEY"), os.Getenv("FYB_SECRET")),
+		json:   *asJSON,
+		yes:    *yes,
+		stdin:  bufio.NewReader(stdin),
+		stdout: stdout,
+	}
+
+	cmd, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "ticker":
+		return c.ticker(cmdArgs)
+	case "book":
+		return c.book(cmdArgs)
+	case "trades":
+		return c.trades(cmdArgs)
+	case "balance":
+		return c.balance(cmdArgs)
+	case "orders":
+		return c.orders(cmdArgs)
+	case "history":
+		return c.history(cmdArgs)
+	case "buy", "sell":
+		return c.place(cmd, cmdArgs)
+	case "cancel":
+		return c.cancel(cmdArgs)
+	case "withdraw":
+		return c.withdraw(cmdArgs)
+	}
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+// ask prints prompt and reports whether the user answered yes.
+func (c *cli) ask(prompt string) bool {
+	if c.yes {
+		return true
+	}
+	fmt.Fprintf(c.stdout, "%s [y/N] ", prompt)
+	line, _ := c.stdin.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+func (c *cli) printJSON(v interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
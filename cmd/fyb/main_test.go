@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, hits *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits = append(*hits, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/SGD/tickerdetailed.json":
+			w.Write([]byte(`{"ask":"1012.48","bid":"1005.17","last":"1011.00","vol":"1.2"}`))
+		case "/api/SGD/placeorder":
+			w.Write([]byte(`{"error":0,"msg":"","pending_oid":"28"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestTicker(t *testing.T) {
+	var hits []string
+	ts := newTestServer(t, &hits)
+	defer ts.Close()
+
+	var out bytes.Buffer
+	err := run([]string{"-url", ts.URL + "/api/SGD", "ticker"}, strings.NewReader(""), &out, os.Stderr)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "1012.48")
+
+	out.Reset()
+	err = run([]string{"-url", ts.URL + "/api/SGD", "-json", "ticker"}, strings.NewReader(""), &out, os.Stderr)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"ask": "1012.48"`)
+}
+
+func TestBuyConfirmation(t *testing.T) {
+	var hits []string
+	ts := newTestServer(t, &hits)
+	defer ts.Close()
+	os.Setenv("FYB_KEY", "key")
+	os.Setenv("FYB_SECRET", "secret")
+
+	var out bytes.Buffer
+	err := run([]string{"-url", ts.URL + "/api/SGD", "buy", "1.2", "1.1"}, strings.NewReader("n\n"), &out, os.Stderr)
+	require.EqualError(t, err, "aborted")
+	require.Empty(t, hits)
+
+	err = run([]string{"-url", ts.URL + "/api/SGD", "buy", "1.2", "1.1"}, strings.NewReader("y\n"), &out, os.Stderr)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/SGD/placeorder"}, hits)
+	require.Contains(t, out.String(), "placed order 28")
+}
+
+func TestUnknownMarket(t *testing.T) {
+	err := run([]string{"-market", "USD", "ticker"}, strings.NewReader(""), os.Stdout, os.Stderr)
+	require.EqualError(t, err, `unknown market "USD"`)
+}
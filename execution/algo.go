@@ -0,0 +1,324 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Strategy is an execution algorithm.
+type Strategy int
+
+// Strategies.
+const (
+	// TWAP spreads the order evenly over the duration, one slice per
+	// interval.
+	TWAP Strategy = iota
+	// VWAP trades in proportion to the market volume observed since the
+	// start, so the order follows the market's volume profile.
+	VWAP
+)
+
+func (s Strategy) String() string {
+	if s == VWAP {
+		return "vwap"
+	}
+	return "twap"
+}
+
+// AlgoOrder is a parent order to be worked by an Algo.
+type AlgoOrder struct {
+	Side Side
+	Qty  decimal.Decimal
+	// Limit is the worst price a child is placed at. Zero means no limit.
+	Limit decimal.Decimal
+	// Duration is how long the order is worked. Whatever is left at the
+	// end is cancelled.
+	Duration time.Duration
+	// Interval is the time between steps.
+	Interval time.Duration
+	// Participation is the share of the market volume VWAP trades. For
+	// TWAP it optionally caps the traded quantity the same way.
+	Participation decimal.Decimal
+	// RepriceAfter is how long a child may rest before it is cancelled and
+	// its remainder re-placed at the current price. Default is Interval.
+	RepriceAfter time.Duration
+	// MinQty is the smallest child order, except for the last one.
+	// Default is 0.001 BTC.
+	MinQty decimal.Decimal
+	// Aggressive places children at the best opposite price instead of
+	// joining the best price on our side of the book.
+	Aggressive bool
+}
+
+// Progress reports how far an Algo has got.
+type Progress struct {
+	Filled    decimal.Decimal
+	Remaining decimal.Decimal
+	AvgPrice  decimal.Decimal
+	Children  int // child orders placed so far
+	Open      int // child orders resting on the book
+	Done      bool
+}
+
+// Algo works an AlgoOrder by placing child orders through a Manager.
+type Algo struct {
+	strategy Strategy
+	order    AlgoOrder
+	m        *Manager
+
+	// OnProgress, if set, is called after every step.
+	OnProgress func(Progress)
+	// OnError, if set, is called with errors that do not stop the algo,
+	// such as a failed cancel of a stale child.
+	OnError func(err error)
+
+	started   bool
+	start     time.Time
+	children  []int64
+	lastTID   int64
+	marketVol decimal.Decimal
+	done      bool
+}
+
+// NewAlgo returns an Algo working order with strategy.
+func NewAlgo(m *Manager, strategy Strategy, order AlgoOrder) (*Algo, error) {
+	switch {
+	case order.Side != Buy && order.Side != Sell:
+		return nil, fmt.Errorf("invalid side %q", order.Side)
+	case !order.Qty.IsPositive():
+		return nil, errors.New("qty must be positive")
+	case order.Interval <= 0:
+		return nil, errors.New("interval must be positive")
+	case order.Duration < order.Interval:
+		return nil, errors.New("duration must be at least one interval")
+	case strategy == VWAP && !order.Participation.IsPositive():
+		return nil, errors.New("VWAP needs a positive participation")
+	}
+	if order.RepriceAfter == 0 {
+		order.RepriceAfter = order.Interval
+	}
+	if order.MinQty.IsZero() {
+		order.MinQty = decimal.New(1, -3)
+	}
+	return &Algo{strategy: strategy, order: order, m: m}, nil
+}
+
+// Run steps the algo every Interval until the order is done or ctx is
+// cancelled. On return no child is left on the book, unless cancelling it
+// failed, in which case the cancel error is returned.
+func (a *Algo) Run(ctx context.Context) (Progress, error) {
+	ticker := time.NewTicker(a.order.Interval)
+	defer ticker.Stop()
+	for {
+		p, err := a.Step(a.m.now())
+		if err != nil {
+			p, _ = a.stop()
+			return p, err
+		}
+		if p.Done {
+			return p, nil
+		}
+		select {
+		case <-ctx.Done():
+			p, err := a.stop()
+			if err != nil {
+				return p, err
+			}
+			return p, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step syncs fills, re-prices stale children and places a new child if
+// the algo is behind schedule. Run calls it; it can also be driven
+// manually.
+func (a *Algo) Step(now time.Time) (Progress, error) {
+	if a.done {
+		return a.progress(), nil
+	}
+	if err := a.m.Sync(); err != nil {
+		return a.progress(), err
+	}
+	if !a.started {
+		// The first batch of trades only sets the starting point.
+		if _, err := a.newVolume(); err != nil {
+			return a.progress(), err
+		}
+		a.started, a.start = true, now
+	}
+
+	p := a.progress()
+	if !p.Remaining.IsPositive() || now.Sub(a.start) >= a.order.Duration {
+		return a.stop()
+	}
+
+	vol, err := a.newVolume()
+	if err != nil {
+		return p, err
+	}
+	a.marketVol = a.marketVol.Add(vol)
+
+	// Cancel stale children; their remainder is placed again below. A
+	// child that could not be cancelled stays outstanding and is retried
+	// on the next step.
+	for _, ticket := range a.children {
+		if o, ok := a.m.Order(ticket); ok && o.State == Open && now.Sub(o.Placed) >= a.order.RepriceAfter {
+			if err := a.m.Cancel(ticket); err != nil && a.OnError != nil {
+				a.OnError(err)
+			}
+		}
+	}
+
+	p = a.progress()
+	var outstanding decimal.Decimal
+	for _, o := range a.open() {
+		outstanding = outstanding.Add(o.Remaining())
+	}
+	need := a.target(now, p.Filled).Sub(p.Filled).Sub(outstanding).Truncate(QtyPlaces)
+	if need.IsPositive() && (need.GreaterThanOrEqual(a.order.MinQty) || need.Equal(p.Remaining.Sub(outstanding))) {
+		price, ok, err := a.price()
+		if err != nil {
+			return p, err
+		}
+		if ok {
+			o, err := a.m.Place(a.order.Side, price, need)
+			if err != nil {
+				return p, err
+			}
+			a.children = append(a.children, o.Ticket)
+		}
+	}
+
+	p = a.progress()
+	a.report(p)
+	return p, nil
+}
+
+// target returns the quantity that should be filled by now.
+func (a *Algo) target(now time.Time, filled decimal.Decimal) decimal.Decimal {
+	// Our own fills show up in the public trades.
+	market := decimal.Max(a.marketVol.Sub(filled), decimal.Zero)
+	var target decimal.Decimal
+	switch a.strategy {
+	case VWAP:
+		target = a.order.Participation.Mul(market)
+	default:
+		steps := int64(a.order.Duration / a.order.Interval)
+		if steps < 1 {
+			steps = 1
+		}
+		k := int64(now.Sub(a.start)/a.order.Interval) + 1
+		target = a.order.Qty.Mul(decimal.NewFromInt(k)).Div(decimal.NewFromInt(steps))
+		if a.order.Participation.IsPositive() {
+			target = decimal.Min(target, a.order.Participation.Mul(market))
+		}
+	}
+	return decimal.Min(target, a.order.Qty)
+}
+
+// price returns the price for a new child, or false if the book has no
+// usable price.
+func (a *Algo) price() (decimal.Decimal, bool, error) {
+	book, err := a.m.api.GetOrderBook()
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	// Passive buys join the best bid, aggressive buys take the best ask.
+	levels := book.Bids
+	if (a.order.Side == Buy) == a.order.Aggressive {
+		levels = book.Asks
+	}
+	if len(levels) == 0 {
+		return decimal.Zero, false, nil
+	}
+	price := levels[0].Price
+	if a.order.Limit.IsPositive() {
+		if a.order.Side == Buy {
+			price = decimal.Min(price, a.order.Limit)
+		} else {
+			price = decimal.Max(price, a.order.Limit)
+		}
+	}
+	return price, true, nil
+}
+
+// newVolume returns the volume of public trades since the last call.
+func (a *Algo) newVolume() (decimal.Decimal, error) {
+	trades, err := a.m.api.GetTradeHistory(a.lastTID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	var vol decimal.Decimal
+	for _, t := range trades {
+		if t.TID > a.lastTID {
+			a.lastTID = t.TID
+		}
+		vol = vol.Add(t.Amount)
+	}
+	return vol, nil
+}
+
+func (a *Algo) open() []Order {
+	var res []Order
+	for _, ticket := range a.children {
+		if o, ok := a.m.Order(ticket); ok && o.State == Open {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// stop cancels all open children and marks the algo done. If a child
+// cannot be cancelled, the error goes to OnError, the algo is not marked
+// done and the first error is returned.
+func (a *Algo) stop() (Progress, error) {
+	var first error
+	for _, o := range a.open() {
+		if err := a.m.Cancel(o.Ticket); err != nil {
+			if a.OnError != nil {
+				a.OnError(err)
+			}
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	if first == nil {
+		a.done = true
+	}
+	p := a.progress()
+	a.report(p)
+	return p, first
+}
+
+func (a *Algo) progress() Progress {
+	p := Progress{Children: len(a.children), Done: a.done}
+	var value decimal.Decimal
+	for _, ticket := range a.children {
+		o, ok := a.m.Order(ticket)
+		if !ok {
+			continue
+		}
+		p.Filled = p.Filled.Add(o.Filled)
+		value = value.Add(o.Filled.Mul(o.Price))
+		if o.State == Open {
+			p.Open++
+		}
+	}
+	p.Remaining = a.order.Qty.Sub(p.Filled)
+	if p.Filled.IsPositive() {
+		p.AvgPrice = value.Div(p.Filled).Round(PricePlaces)
+	}
+	return p
+}
+
+func (a *Algo) report(p Progress) {
+	if a.OnProgress != nil {
+		a.OnProgress(p)
+	}
+}
@@ -0,0 +1,122 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTWAP(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("100", "102")
+	m := NewManager(api)
+	algo, err := NewAlgo(m, TWAP, AlgoOrder{
+		Side:     Buy,
+		Qty:      d("0.3"),
+		Limit:    d("100.5"),
+		Duration: 3 * time.Minute,
+		Interval: time.Minute,
+	})
+	require.NoError(t, err)
+	start := time.Unix(1000, 0)
+	m.now = func() time.Time { return start }
+
+	p, err := algo.Step(start)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Open)
+	child := m.Open()[0]
+	require.Equal(t, "0.1", child.Qty.String())
+	require.Equal(t, "100", child.Price.String())
+	x.Trade(child.Ticket, "100", "0.1")
+
+	now := start.Add(time.Minute)
+	m.now = func() time.Time { return now }
+	p, err = algo.Step(now)
+	require.NoError(t, err)
+	require.Equal(t, "0.1", p.Filled.String())
+	require.Equal(t, 2, p.Children)
+
+	// The unfilled child is re-priced, capped at the limit.
+	x.SetBook("101", "102")
+	now = start.Add(2 * time.Minute)
+	p, err = algo.Step(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Open)
+	child = m.Open()[0]
+	require.Equal(t, "0.2", child.Qty.String())
+	require.Equal(t, "100.5", child.Price.String())
+
+	now = start.Add(3 * time.Minute)
+	p, err = algo.Step(now)
+	require.NoError(t, err)
+	require.True(t, p.Done)
+	require.Equal(t, "0.2", p.Remaining.String())
+	require.Equal(t, "100", p.AvgPrice.String())
+	require.Empty(t, x.Resting())
+}
+
+func TestVWAP(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("100", "102")
+	m := NewManager(api)
+	algo, err := NewAlgo(m, VWAP, AlgoOrder{
+		Side:          Sell,
+		Qty:           d("2"),
+		Duration:      time.Hour,
+		Interval:      time.Minute,
+		Participation: d("0.5"),
+		Aggressive:    true,
+	})
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+
+	p, err := algo.Step(now)
+	require.NoError(t, err)
+	require.Equal(t, 0, p.Children, "no market volume yet")
+
+	x.Trade(0, "101", "1")
+	p, err = algo.Step(now.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Children)
+	child := m.Open()[0]
+	require.Equal(t, "0.5", child.Qty.String())
+	require.Equal(t, "100", child.Price.String())
+}
+
+func TestNewAlgoValidates(t *testing.T) {
+	_, api := exectest.New(t)
+	m := NewManager(api)
+	_, err := NewAlgo(m, TWAP, AlgoOrder{Side: Buy, Qty: d("1"), Duration: time.Hour})
+	require.EqualError(t, err, "interval must be positive")
+	_, err = NewAlgo(m, VWAP, AlgoOrder{Side: Buy, Qty: d("1"), Duration: time.Hour, Interval: time.Minute})
+	require.Error(t, err)
+}
+
+func TestAlgoRunReportsCancelError(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("100", "102")
+	m := NewManager(api)
+	algo, err := NewAlgo(m, TWAP, AlgoOrder{
+		Side:     Buy,
+		Qty:      d("0.3"),
+		Limit:    d("100.5"),
+		Duration: time.Hour,
+		Interval: time.Minute,
+	})
+	require.NoError(t, err)
+	var errs []error
+	algo.OnError = func(err error) { errs = append(errs, err) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	x.FailCancels(1)
+	p, err := algo.Run(ctx)
+	require.Error(t, err)
+	require.NotEqual(t, context.Canceled, err)
+	require.Len(t, errs, 1)
+	require.False(t, p.Done)
+	require.Equal(t, 1, p.Open)
+}
@@ -0,0 +1,226 @@
+// Package exectest provides a fake FYB exchange for testing code built on
+// package execution.
+package exectest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Order is an order resting on an Exchange.
+type Order struct {
+	Side  string // "B" or "S"
+	Price decimal.Decimal
+	Qty   decimal.Decimal // unfilled quantity
+	Date  int64           // unix time the order was placed
+}
+
+// Exchange is a fake FYB market. Orders rest until filled with Trade or
+// Fill or cancelled; nothing is matched automatically. Executed orders are
+// reported by the order history with status "F".
+type Exchange struct {
+	mu          sync.Mutex
+	book        fyb.OrderBook
+	last        decimal.Decimal
+	trades      fyb.Trades
+	orders      map[int64]*Order
+	executed    map[int64]*Order // executed quantity by ticket
+	next        int64
+	cancelled   []int64
+	failCancels int
+	failPlaces  int
+}
+
+// New starts an Exchange, which is closed when the test ends, and returns
+// it with a client for it.
+func New(t testing.TB) (*Exchange, *fyb.Fyb) {
+	x := &Exchange{orders: map[int64]*Order{}, executed: map[int64]*Order{}, next: 1}
+	ts := httptest.NewServer(x)
+	t.Cleanup(ts.Close)
+	return x, fyb.New(ts.URL, "key", "secret")
+}
+
+// SetBook replaces the order book with one bid and one ask level of 5 BTC.
+func (x *Exchange) SetBook(bid, ask string) {
+	x.SetLevels(
+		[]fyb.PriceAmount{{Price: decimal.RequireFromString(bid), Amount: decimal.NewFromInt(5)}},
+		[]fyb.PriceAmount{{Price: decimal.RequireFromString(ask), Amount: decimal.NewFromInt(5)}},
+	)
+}
+
+// SetLevels replaces the order book.
+func (x *Exchange) SetLevels(bids, asks []fyb.PriceAmount) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.book = fyb.OrderBook{Bids: bids, Asks: asks}
+}
+
+// Trade prints a public trade, filling qty of the order ticket if it rests
+// on the exchange. Ticket 0 prints a trade of someone else.
+func (x *Exchange) Trade(ticket int64, price, qty string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.trade(ticket, decimal.RequireFromString(price), decimal.RequireFromString(qty))
+}
+
+// Fill fills the rest of order ticket at its price.
+func (x *Exchange) Fill(ticket int64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if o, ok := x.orders[ticket]; ok {
+		x.trade(ticket, o.Price, o.Qty)
+	}
+}
+
+func (x *Exchange) trade(ticket int64, price, qty decimal.Decimal) {
+	if o, ok := x.orders[ticket]; ok {
+		e, ok := x.executed[ticket]
+		if !ok {
+			e = &Order{Side: o.Side, Price: o.Price, Date: o.Date}
+			x.executed[ticket] = e
+		}
+		e.Qty = e.Qty.Add(qty)
+		o.Qty = o.Qty.Sub(qty)
+		if !o.Qty.IsPositive() {
+			delete(x.orders, ticket)
+		}
+	}
+	x.last = price
+	x.trades = append(x.trades, fyb.Trade{TID: int64(len(x.trades) + 1), Date: 1, Price: price, Amount: qty})
+}
+
+// Resting returns the resting orders by ticket.
+func (x *Exchange) Resting() map[int64]Order {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	res := map[int64]Order{}
+	for ticket, o := range x.orders {
+		res[ticket] = *o
+	}
+	return res
+}
+
+// Cancelled returns the tickets cancelled so far, in order.
+func (x *Exchange) Cancelled() []int64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return append([]int64(nil), x.cancelled...)
+}
+
+// FailCancels makes the next n cancel requests fail with a server error.
+func (x *Exchange) FailCancels(n int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.failCancels = n
+}
+
+// FailPlaces makes the next n place requests fail with a server error
+// after placing the order, as if the response was lost.
+func (x *Exchange) FailPlaces(n int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.failPlaces = n
+}
+
+// ServeHTTP implements the FYB API endpoints used by package execution.
+func (x *Exchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	enc := json.NewEncoder(w)
+	switch r.URL.Path {
+	case "/orderbook.json":
+		// FYB sends levels as [price, amount] arrays.
+		levels := func(ls []fyb.PriceAmount) [][2]json.Number {
+			res := [][2]json.Number{}
+			for _, l := range ls {
+				res = append(res, [2]json.Number{json.Number(l.Price.String()), json.Number(l.Amount.String())})
+			}
+			return res
+		}
+		enc.Encode(map[string]interface{}{"asks": levels(x.book.Asks), "bids": levels(x.book.Bids)})
+	case "/tickerdetailed.json":
+		bid, ask := x.last, x.last
+		if len(x.book.Bids) > 0 && len(x.book.Asks) > 0 {
+			bid, ask = x.book.Bids[0].Price, x.book.Asks[0].Price
+		}
+		enc.Encode(fyb.Ticker{Ask: ask, Bid: bid, Last: x.last})
+	case "/trades.json":
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		res := fyb.Trades{}
+		for _, t := range x.trades {
+			if t.TID > since {
+				res = append(res, t)
+			}
+		}
+		enc.Encode(res)
+	case "/getpendingorders":
+		var orders []map[string]interface{}
+		for _, ticket := range sortedTickets(x.orders, false) {
+			o := x.orders[ticket]
+			orders = append(orders, map[string]interface{}{
+				"date": o.Date, "price": o.Price.StringFixed(2), "qty": o.Qty.StringFixed(8), "ticket": ticket, "type": o.Side,
+			})
+		}
+		enc.Encode(map[string]interface{}{"error": 0, "orders": orders})
+	case "/getorderhistory":
+		orders := []fyb.HistoryOrder{}
+		for _, ticket := range sortedTickets(x.executed, true) {
+			e := x.executed[ticket]
+			orders = append(orders, fyb.HistoryOrder{
+				DateCreated: e.Date, DateExecuted: e.Date, Price: "S$" + e.Price.StringFixed(2), Qty: e.Qty.StringFixed(8) + "BTC",
+				Status: "F", Type: e.Side, Ticket: ticket,
+			})
+		}
+		enc.Encode(fyb.OrderHistoryResponse{Orders: orders})
+	case "/placeorder":
+		ticket := x.next
+		x.next++
+		x.orders[ticket] = &Order{
+			Side:  r.FormValue("type"),
+			Price: decimal.RequireFromString(r.FormValue("price")),
+			Qty:   decimal.RequireFromString(r.FormValue("qty")),
+			Date:  time.Now().Unix(),
+		}
+		if x.failPlaces > 0 {
+			x.failPlaces--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"error":0,"msg":"","pending_oid":"%d"}`, ticket)
+	case "/cancelpendingorder":
+		if x.failCancels > 0 {
+			x.failCancels--
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		ticket, _ := strconv.ParseInt(r.FormValue("orderNo"), 10, 64)
+		if _, ok := x.orders[ticket]; !ok {
+			fmt.Fprint(w, `{"error":1,"msg":"no such order"}`)
+			return
+		}
+		delete(x.orders, ticket)
+		x.cancelled = append(x.cancelled, ticket)
+		fmt.Fprint(w, `{"error":0}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func sortedTickets(orders map[int64]*Order, newestFirst bool) []int64 {
+	var tickets []int64
+	for ticket := range orders {
+		tickets = append(tickets, ticket)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return (tickets[i] < tickets[j]) != newestFirst })
+	return tickets
+}
@@ -0,0 +1,155 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// IcebergOrder is a large limit order shown on the book one slice at a
+// time.
+type IcebergOrder struct {
+	Side  Side
+	Price decimal.Decimal
+	Qty   decimal.Decimal // total quantity
+	// Display is the average visible quantity.
+	Display decimal.Decimal
+	// Variance randomizes the visible quantity by up to this fraction of
+	// Display in either direction, e.g. 0.2 for ±20%, so the slices are
+	// harder to spot. It must be less than 1.
+	Variance decimal.Decimal
+}
+
+// Iceberg works an IcebergOrder. When the visible slice has filled
+// completely, the next one is placed.
+type Iceberg struct {
+	order IcebergOrder
+	m     *Manager
+	rnd   func() float64
+
+	// OnProgress, if set, is called after every step.
+	OnProgress func(Progress)
+
+	slices []int64
+	done   bool
+}
+
+// NewIceberg returns an Iceberg placing order through m.
+func NewIceberg(m *Manager, order IcebergOrder) (*Iceberg, error) {
+	switch {
+	case order.Side != Buy && order.Side != Sell:
+		return nil, fmt.Errorf("invalid side %q", order.Side)
+	case !order.Price.IsPositive():
+		return nil, errors.New("price must be positive")
+	case !order.Qty.IsPositive():
+		return nil, errors.New("qty must be positive")
+	case !order.Display.Truncate(QtyPlaces).IsPositive():
+		return nil, errors.New("display must be positive")
+	case order.Variance.IsNegative() || order.Variance.GreaterThanOrEqual(decimal.NewFromInt(1)):
+		return nil, errors.New("variance must be at least 0 and less than 1")
+	}
+	return &Iceberg{
+		order: order,
+		m:     m,
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+	}, nil
+}
+
+// Run steps the iceberg every interval until it is filled or ctx is
+// cancelled, in which case the visible slice is cancelled.
+func (i *Iceberg) Run(ctx context.Context, interval time.Duration) (Progress, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p, err := i.Step()
+		if err != nil || p.Done {
+			return p, err
+		}
+		select {
+		case <-ctx.Done():
+			p, err := i.Cancel()
+			if err != nil {
+				return p, err
+			}
+			return p, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step syncs fills and places the next slice if the visible one is gone.
+func (i *Iceberg) Step() (Progress, error) {
+	if i.done {
+		return i.progress(), nil
+	}
+	if err := i.m.Sync(); err != nil {
+		return i.progress(), err
+	}
+	p := i.progress()
+	if p.Open == 0 {
+		if !p.Remaining.IsPositive() {
+			i.done = true
+			p = i.progress()
+		} else {
+			o, err := i.m.Place(i.order.Side, i.order.Price, decimal.Min(i.displaySize(), p.Remaining))
+			if err != nil {
+				return p, err
+			}
+			i.slices = append(i.slices, o.Ticket)
+			p = i.progress()
+		}
+	}
+	if i.OnProgress != nil {
+		i.OnProgress(p)
+	}
+	return p, nil
+}
+
+// Cancel cancels the visible slice and stops the iceberg. If the slice
+// cannot be cancelled, the iceberg is not stopped and Cancel can be
+// retried.
+func (i *Iceberg) Cancel() (Progress, error) {
+	for _, ticket := range i.slices {
+		if o, ok := i.m.Order(ticket); ok && o.State == Open {
+			if err := i.m.Cancel(ticket); err != nil {
+				return i.progress(), err
+			}
+		}
+	}
+	i.done = true
+	return i.progress(), nil
+}
+
+// displaySize returns a random visible quantity around Display.
+func (i *Iceberg) displaySize() decimal.Decimal {
+	// factor is uniform in [1-Variance, 1+Variance].
+	factor := decimal.NewFromInt(1).Add(i.order.Variance.Mul(decimal.NewFromFloat(2*i.rnd() - 1)))
+	size := i.order.Display.Mul(factor).Truncate(QtyPlaces)
+	if !size.IsPositive() {
+		size = i.order.Display
+	}
+	return size
+}
+
+func (i *Iceberg) progress() Progress {
+	p := Progress{Children: len(i.slices), Done: i.done}
+	for _, ticket := range i.slices {
+		o, ok := i.m.Order(ticket)
+		if !ok {
+			continue
+		}
+		p.Filled = p.Filled.Add(o.Filled)
+		if o.State == Open {
+			p.Open++
+		}
+	}
+	p.Remaining = i.order.Qty.Sub(p.Filled)
+	if p.Filled.IsPositive() {
+		p.AvgPrice = i.order.Price
+	}
+	return p
+}
@@ -0,0 +1,67 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIceberg(t *testing.T) {
+	x, api := exectest.New(t)
+	m := NewManager(api)
+	ice, err := NewIceberg(m, IcebergOrder{Side: Sell, Price: d("105"), Qty: d("1"), Display: d("0.4"), Variance: d("0.25")})
+	require.NoError(t, err)
+	rnd := []float64{1, 0, 0.5}
+	ice.rnd = func() float64 {
+		r := rnd[0]
+		rnd = rnd[1:]
+		return r
+	}
+
+	p, err := ice.Step()
+	require.NoError(t, err)
+	visible := m.Open()[0]
+	require.Equal(t, "0.5", visible.Qty.String(), "0.4 + 25%")
+
+	// A partly filled slice is not replenished.
+	x.Trade(visible.Ticket, "105", "0.2")
+	p, err = ice.Step()
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Children)
+
+	x.Trade(visible.Ticket, "105", "0.3")
+	p, err = ice.Step()
+	require.NoError(t, err)
+	require.Equal(t, 2, p.Children)
+	visible = m.Open()[0]
+	require.Equal(t, "0.3", visible.Qty.String(), "0.4 - 25%")
+
+	x.Trade(visible.Ticket, "105", "0.3")
+	p, err = ice.Step()
+	require.NoError(t, err)
+	visible = m.Open()[0]
+	require.Equal(t, "0.2", visible.Qty.String(), "capped at the remaining quantity")
+	require.Len(t, rnd, 0)
+
+	x.FailCancels(1)
+	p, err = ice.Cancel()
+	require.Error(t, err)
+	require.False(t, p.Done, "the slice is still on the book")
+	require.Len(t, x.Resting(), 1)
+
+	p, err = ice.Cancel()
+	require.NoError(t, err)
+	require.True(t, p.Done)
+	require.Equal(t, "0.8", p.Filled.String())
+	require.Empty(t, x.Resting())
+}
+
+func TestNewIcebergValidates(t *testing.T) {
+	_, api := exectest.New(t)
+	m := NewManager(api)
+	_, err := NewIceberg(m, IcebergOrder{Side: Sell, Price: d("105"), Qty: d("1"), Display: d("0.4"), Variance: d("1")})
+	require.EqualError(t, err, "variance must be at least 0 and less than 1")
+	_, err = NewIceberg(m, IcebergOrder{Side: Sell, Price: d("105"), Qty: d("1")})
+	require.EqualError(t, err, "display must be positive")
+}
@@ -0,0 +1,421 @@
+// Package execution places and tracks orders on FYB and builds execution
+// strategies on top: market orders with price protection, TWAP and VWAP
+// slicing, iceberg orders and client-side stop-loss, take-profit and
+// trailing-stop triggers.
+//
+// FYB only has limit orders and reports no fills, so a Manager infers
+// fills from the pending order list: an order whose pending quantity
+// shrinks was partly filled. An order that disappears from the list is
+// looked up in the order history, which tells how much of it executed
+// before it was filled or cancelled.
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Side is the side of an order, as in FYB's order type field.
+type Side string
+
+// Sides.
+const (
+	Buy  Side = "B"
+	Sell Side = "S"
+)
+
+// Opposite returns the other side.
+func (s Side) Opposite() Side {
+	if s == Buy {
+		return Sell
+	}
+	return Buy
+}
+
+// Precision of prices and quantities sent to FYB. PlaceOrder formats both
+// with six decimals.
+const (
+	PricePlaces = 2
+	QtyPlaces   = 6
+)
+
+// State is the state of an order.
+type State int
+
+// Order states.
+const (
+	Open State = iota
+	Filled
+	Cancelled
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case Filled:
+		return "filled"
+	case Cancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Order is an order placed through a Manager.
+type Order struct {
+	Ticket int64
+	Side   Side
+	Price  decimal.Decimal
+	Qty    decimal.Decimal
+	Filled decimal.Decimal
+	Placed time.Time
+	State  State
+}
+
+// Remaining returns the unfilled quantity.
+func (o Order) Remaining() decimal.Decimal {
+	return o.Qty.Sub(o.Filled)
+}
+
+// Fill is a fill detected by Manager.Sync.
+//
+// FYB reports no execution prices, so Price is the order's limit price. An
+// order priced through several levels of the book, such as a market order,
+// may have filled at better prices than that.
+type Fill struct {
+	Order Order // the order after the fill
+	Qty   decimal.Decimal
+	Price decimal.Decimal // the order's limit price
+	Time  time.Time
+}
+
+// statusFilled is the order history status of executed orders.
+const statusFilled = "F"
+
+// Manager places orders and tracks their fills. It is safe for concurrent
+// use: placing, cancelling and syncing are serialized, so a Sync never
+// sees the pending list from before an order it knows about was placed.
+//
+// Orders are kept after they are filled or cancelled, so their fills can
+// be looked up; long-running users call Forget once they are done with an
+// order.
+type Manager struct {
+	api *fyb.Fyb
+	now func() time.Time
+
+	ops sync.Mutex // serializes requests that change or read order state
+
+	mu           sync.Mutex
+	historyLimit int64
+	orders       map[int64]*Order
+	handlers     []func(Fill)
+}
+
+// NewManager returns a Manager placing orders through api.
+func NewManager(api *fyb.Fyb) *Manager {
+	return &Manager{
+		api:          api,
+		now:          time.Now,
+		historyLimit: 100,
+		orders:       map[int64]*Order{},
+	}
+}
+
+// SetHistoryLimit sets how many of the newest orders are fetched from the
+// order history to settle orders that left the pending list. Default is
+// 100.
+func (m *Manager) SetHistoryLimit(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.historyLimit = n
+}
+
+// OnFill registers fn to be called for every fill found by Sync.
+func (m *Manager) OnFill(fn func(Fill)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, fn)
+}
+
+// UncertainPlaceError is returned by Place when the request failed after
+// it may have reached FYB, e.g. on a timeout, so the order may have been
+// placed. Placed tells.
+type UncertainPlaceError struct {
+	Err error
+}
+
+func (e *UncertainPlaceError) Error() string {
+	return fmt.Sprintf("order may have been placed: %v", e.Err)
+}
+
+// placedSkew is how much earlier than asked Placed looks, to allow for the
+// difference between the local and the FYB clock.
+const placedSkew = time.Minute
+
+// Place places a limit order. Price and quantity are rounded to the
+// precision FYB accepts. If the request fails after it may have been sent,
+// the error is an *UncertainPlaceError.
+func (m *Manager) Place(side Side, price, qty decimal.Decimal) (Order, error) {
+	price, qty = price.Round(PricePlaces), qty.Truncate(QtyPlaces)
+	if !price.IsPositive() || !qty.IsPositive() {
+		return Order{}, fmt.Errorf("invalid order %s %s @ %s", side, qty, price)
+	}
+	m.ops.Lock()
+	defer m.ops.Unlock()
+	res, err := m.api.PlaceOrder(string(side), price.InexactFloat64(), qty.InexactFloat64())
+	if err != nil {
+		var open *fyb.CircuitOpenError
+		if errors.As(err, &open) {
+			// Failed without contacting FYB.
+			return Order{}, err
+		}
+		return Order{}, &UncertainPlaceError{Err: err}
+	}
+	if res.Error != 0 {
+		return Order{}, fmt.Errorf("FYB error %d: %s", res.Error, res.Msg)
+	}
+	ticket, err := strconv.ParseInt(res.PendingOID, 10, 64)
+	if err != nil {
+		return Order{}, &UncertainPlaceError{Err: fmt.Errorf("unexpected pending_oid %q", res.PendingOID)}
+	}
+	o := &Order{Ticket: ticket, Side: side, Price: price, Qty: qty, Placed: m.now()}
+	m.mu.Lock()
+	m.orders[ticket] = o
+	m.mu.Unlock()
+	return *o, nil
+}
+
+// Placed looks for an order that a Place failing with an
+// *UncertainPlaceError may have placed: an order of side and price, for at
+// most qty, created since then and not known to the Manager. The pending
+// orders and the order history are searched. If there is one, the Manager
+// takes it over and syncs, so its fills are reported.
+func (m *Manager) Placed(side Side, price, qty decimal.Decimal, since time.Time) (Order, bool, error) {
+	price, qty = price.Round(PricePlaces), qty.Truncate(QtyPlaces)
+	m.ops.Lock()
+	defer m.ops.Unlock()
+	pending, err := m.api.GetPendingOrders()
+	if err == nil && pending.Error != 0 {
+		err = fmt.Errorf("FYB error %d: %s", pending.Error, pending.Msg)
+	}
+	if err != nil {
+		return Order{}, false, err
+	}
+	m.mu.Lock()
+	limit := m.historyLimit
+	m.mu.Unlock()
+	history, err := m.api.GetOrderHistory(limit)
+	if err == nil && history.Error != 0 {
+		err = fmt.Errorf("FYB error %d: %s", history.Error, history.Msg)
+	}
+	if err != nil {
+		return Order{}, false, err
+	}
+
+	type candidate struct {
+		ticket, date    int64
+		typ, price, qty string
+	}
+	var candidates []candidate
+	for _, p := range pending.Orders {
+		candidates = append(candidates, candidate{p.Ticket, p.Date, p.Type, p.Price, p.Qty})
+	}
+	for _, h := range history.Orders {
+		candidates = append(candidates, candidate{h.Ticket, h.DateCreated, h.Type, h.Price, h.Qty})
+	}
+	cutoff := since.Add(-placedSkew).Unix()
+	for _, c := range candidates {
+		if c.typ != string(side) || c.date < cutoff {
+			continue
+		}
+		if _, ok := m.Order(c.ticket); ok {
+			continue
+		}
+		p, err := fyb.ParseAmount(c.price)
+		if err != nil {
+			return Order{}, false, err
+		}
+		q, err := fyb.ParseAmount(c.qty)
+		if err != nil {
+			return Order{}, false, err
+		}
+		if !p.Equal(price) || q.GreaterThan(qty) {
+			continue
+		}
+		m.mu.Lock()
+		m.orders[c.ticket] = &Order{Ticket: c.ticket, Side: side, Price: price, Qty: qty, Placed: m.now()}
+		m.mu.Unlock()
+		err = m.sync()
+		o, _ := m.Order(c.ticket)
+		return o, true, err
+	}
+	return Order{}, false, nil
+}
+
+// Cancel cancels an open order and syncs, so fills up to the cancel are
+// reported and the order ends up Cancelled or, if it filled first, Filled.
+// It returns an error only if the order is still open afterwards.
+func (m *Manager) Cancel(ticket int64) error {
+	m.ops.Lock()
+	defer m.ops.Unlock()
+	res, err := m.api.CancelPendingOrder(ticket)
+	if err == nil && res.Error != 0 {
+		// Most likely the order filled; the sync below tells.
+		err = fmt.Errorf("FYB error %d: %s", res.Error, res.Msg)
+	}
+	serr := m.sync()
+	if o, ok := m.Order(ticket); !ok || o.State != Open {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// Sync fetches the pending orders, updates the fills of open orders and
+// calls the fill handlers.
+func (m *Manager) Sync() error {
+	m.ops.Lock()
+	defer m.ops.Unlock()
+	return m.sync()
+}
+
+// sync implements Sync. Called with m.ops held.
+func (m *Manager) sync() error {
+	res, err := m.api.GetPendingOrders()
+	if err != nil {
+		return err
+	}
+	if res.Error != 0 {
+		return fmt.Errorf("FYB error %d: %s", res.Error, res.Msg)
+	}
+	pending := map[int64]decimal.Decimal{}
+	for _, p := range res.Orders {
+		qty, err := fyb.ParseAmount(p.Qty)
+		if err != nil {
+			return err
+		}
+		pending[p.Ticket] = qty
+	}
+
+	m.mu.Lock()
+	missing := false
+	for ticket, o := range m.orders {
+		if _, ok := pending[ticket]; !ok && o.State == Open {
+			missing = true
+		}
+	}
+	m.mu.Unlock()
+	var executed map[int64]decimal.Decimal
+	if missing {
+		if executed, err = m.executed(); err != nil {
+			return err
+		}
+	}
+
+	now := m.now()
+	var fills []Fill
+	m.mu.Lock()
+	for ticket, o := range m.orders {
+		if o.State != Open {
+			continue
+		}
+		var filled decimal.Decimal
+		if remaining, ok := pending[ticket]; ok {
+			filled = o.Qty.Sub(remaining)
+		} else {
+			filled = decimal.Min(executed[ticket], o.Qty)
+			if filled.Equal(o.Qty) {
+				o.State = Filled
+			} else {
+				// Cancelled, possibly outside the Manager.
+				o.State = Cancelled
+			}
+		}
+		if filled.GreaterThan(o.Filled) {
+			qty := filled.Sub(o.Filled)
+			o.Filled = filled
+			fills = append(fills, Fill{Order: *o, Qty: qty, Price: o.Price, Time: now})
+		}
+	}
+	handlers := append([]func(Fill){}, m.handlers...)
+	m.mu.Unlock()
+
+	sort.Slice(fills, func(i, j int) bool { return fills[i].Order.Ticket < fills[j].Order.Ticket })
+	for _, f := range fills {
+		for _, fn := range handlers {
+			fn(f)
+		}
+	}
+	return nil
+}
+
+// executed returns the executed quantity by ticket from the newest orders
+// of the order history.
+func (m *Manager) executed() (map[int64]decimal.Decimal, error) {
+	m.mu.Lock()
+	limit := m.historyLimit
+	m.mu.Unlock()
+	res, err := m.api.GetOrderHistory(limit)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != 0 {
+		return nil, fmt.Errorf("FYB error %d: %s", res.Error, res.Msg)
+	}
+	executed := map[int64]decimal.Decimal{}
+	for _, h := range res.Orders {
+		if h.Status != statusFilled {
+			continue
+		}
+		qty, err := fyb.ParseAmount(h.Qty)
+		if err != nil {
+			return nil, err
+		}
+		executed[h.Ticket] = executed[h.Ticket].Add(qty)
+	}
+	return executed, nil
+}
+
+// Order returns the order with the given ticket.
+func (m *Manager) Order(ticket int64) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[ticket]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Forget drops a filled or cancelled order, whose fills have all been
+// delivered. Open orders are kept.
+func (m *Manager) Forget(ticket int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o, ok := m.orders[ticket]; ok && o.State != Open {
+		delete(m.orders, ticket)
+	}
+}
+
+// Open returns the open orders, oldest ticket first.
+func (m *Manager) Open() []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []Order
+	for _, o := range m.orders {
+		if o.State == Open {
+			res = append(res, *o)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Ticket < res[j].Ticket })
+	return res
+}
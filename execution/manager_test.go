@@ -0,0 +1,102 @@
+package execution
+
+import (
+	"testing"
+
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestManager(t *testing.T) {
+	x, api := exectest.New(t)
+	m := NewManager(api)
+	var fills []Fill
+	m.OnFill(func(f Fill) { fills = append(fills, f) })
+
+	a, err := m.Place(Buy, d("100.004"), d("1.0000004"))
+	require.NoError(t, err)
+	require.Equal(t, "100", a.Price.String())
+	require.Equal(t, "1", a.Qty.String())
+	b, err := m.Place(Sell, d("110"), d("0.5"))
+	require.NoError(t, err)
+
+	x.Trade(a.Ticket, "100", "0.4")
+	x.Trade(b.Ticket, "110", "0.5")
+	require.NoError(t, m.Sync())
+	require.Len(t, fills, 2)
+	require.Equal(t, a.Ticket, fills[0].Order.Ticket)
+	require.Equal(t, "0.4", fills[0].Qty.String())
+	require.Equal(t, Open, fills[0].Order.State)
+	require.Equal(t, Filled, fills[1].Order.State)
+
+	require.NoError(t, m.Cancel(a.Ticket))
+	o, _ := m.Order(a.Ticket)
+	require.Equal(t, Cancelled, o.State)
+	require.Equal(t, "0.6", o.Remaining().String())
+	require.Empty(t, m.Open())
+
+	// Cancelling a filled order is not an error.
+	require.NoError(t, m.Cancel(b.Ticket))
+	o, _ = m.Order(b.Ticket)
+	require.Equal(t, Filled, o.State)
+}
+
+func TestManagerSettlesFromHistory(t *testing.T) {
+	x, api := exectest.New(t)
+	m := NewManager(api)
+	var fills []Fill
+	m.OnFill(func(f Fill) { fills = append(fills, f) })
+
+	a, err := m.Place(Buy, d("100"), d("1"))
+	require.NoError(t, err)
+	require.NoError(t, m.Sync())
+
+	// A partial fill between the last Sync and the cancel is not lost.
+	x.Trade(a.Ticket, "100", "0.3")
+	require.NoError(t, m.Cancel(a.Ticket))
+	o, _ := m.Order(a.Ticket)
+	require.Equal(t, Cancelled, o.State)
+	require.Equal(t, "0.3", o.Filled.String())
+	require.Len(t, fills, 1)
+
+	// An order cancelled outside the Manager is not taken as filled.
+	b, err := m.Place(Sell, d("110"), d("1"))
+	require.NoError(t, err)
+	_, err = api.CancelPendingOrder(b.Ticket)
+	require.NoError(t, err)
+	require.NoError(t, m.Sync())
+	o, _ = m.Order(b.Ticket)
+	require.Equal(t, Cancelled, o.State)
+	require.True(t, o.Filled.IsZero())
+	require.Len(t, fills, 1)
+}
+
+func TestManagerForget(t *testing.T) {
+	x, api := exectest.New(t)
+	m := NewManager(api)
+
+	a, err := m.Place(Buy, d("100"), d("1"))
+	require.NoError(t, err)
+	b, err := m.Place(Sell, d("110"), d("1"))
+	require.NoError(t, err)
+
+	// Open orders are kept.
+	m.Forget(a.Ticket)
+	_, ok := m.Order(a.Ticket)
+	require.True(t, ok)
+
+	x.Trade(b.Ticket, "110", "1")
+	require.NoError(t, m.Cancel(a.Ticket))
+	require.NoError(t, m.Sync())
+	m.Forget(a.Ticket)
+	m.Forget(b.Ticket)
+	_, ok = m.Order(a.Ticket)
+	require.False(t, ok)
+	_, ok = m.Order(b.Ticket)
+	require.False(t, ok)
+}
@@ -0,0 +1,165 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// MarketOptions bound a market order emulated with a limit order.
+type MarketOptions struct {
+	// MaxSlippage is how far beyond the best opposite price the order may
+	// trade, as a fraction, e.g. 0.01 for 1%. Zero allows only the best
+	// price level.
+	MaxSlippage decimal.Decimal
+	// Timeout is how long the order may rest before the unfilled remainder
+	// is cancelled. Default is 10s.
+	Timeout time.Duration
+	// PollInterval is how often fills are checked. Default is 1s.
+	PollInterval time.Duration
+}
+
+// MarketBuy buys qty BTC at the best available prices within the slippage
+// bound. It returns the order once it has filled or its remainder has been
+// cancelled. The order's Price is the limit it was placed at, the worst
+// price it could have traded at, not the average fill price.
+func (m *Manager) MarketBuy(ctx context.Context, qty decimal.Decimal, opts MarketOptions) (Order, error) {
+	return m.market(ctx, Buy, qty, decimal.Zero, opts)
+}
+
+// MarketBuyNotional buys BTC for up to notional in fiat, like MarketBuy.
+func (m *Manager) MarketBuyNotional(ctx context.Context, notional decimal.Decimal, opts MarketOptions) (Order, error) {
+	return m.market(ctx, Buy, decimal.Zero, notional, opts)
+}
+
+// MarketSell sells qty BTC, like MarketBuy.
+func (m *Manager) MarketSell(ctx context.Context, qty decimal.Decimal, opts MarketOptions) (Order, error) {
+	return m.market(ctx, Sell, qty, decimal.Zero, opts)
+}
+
+func (m *Manager) market(ctx context.Context, side Side, qty, notional decimal.Decimal, opts MarketOptions) (Order, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	book, err := m.api.GetOrderBook()
+	if err != nil {
+		return Order{}, err
+	}
+	levels := book.Asks
+	if side == Sell {
+		levels = book.Bids
+	}
+	price, qty, err := marketable(side, levels, qty, notional, opts.MaxSlippage)
+	if err != nil {
+		return Order{}, err
+	}
+	o, err := m.Place(side, price, qty)
+	if err != nil {
+		return Order{}, err
+	}
+	return m.await(ctx, o.Ticket, opts)
+}
+
+// marketable returns the limit price that takes levels up to qty, or up
+// to notional if qty is zero, without going beyond the slippage bound, and
+// the quantity to order. If the levels within the bound are too thin, the
+// order is priced at the bound and its remainder rests.
+func marketable(side Side, levels []fyb.PriceAmount, qty, notional, slippage decimal.Decimal) (price, size decimal.Decimal, err error) {
+	if len(levels) == 0 {
+		return price, size, ErrNoLiquidity
+	}
+	bound := slippageBound(side, levels[0].Price, slippage)
+	within := func(p decimal.Decimal) bool {
+		if side == Buy {
+			return p.LessThanOrEqual(bound)
+		}
+		return p.GreaterThanOrEqual(bound)
+	}
+
+	byNotional := qty.IsZero()
+	left := qty
+	if byNotional {
+		left = notional
+	}
+	for _, l := range levels {
+		if !left.IsPositive() || !within(l.Price) {
+			break
+		}
+		price = l.Price
+		take := l.Amount
+		if byNotional {
+			take = decimal.Min(take, left.Div(l.Price))
+			left = left.Sub(take.Mul(l.Price))
+		} else {
+			take = decimal.Min(take, left)
+			left = left.Sub(take)
+		}
+		size = size.Add(take)
+	}
+	if left.IsPositive() {
+		price = bound
+		if byNotional {
+			size = size.Add(left.Div(bound))
+		} else {
+			size = size.Add(left)
+		}
+	}
+	size = size.Truncate(QtyPlaces)
+	if !size.IsPositive() {
+		return price, size, errors.New("market order quantity rounds to zero")
+	}
+	return price, size, nil
+}
+
+// slippageBound returns the worst price an order of side may trade at:
+// best moved by slippage against the order and rounded towards best to
+// the precision FYB accepts.
+func slippageBound(side Side, best, slippage decimal.Decimal) decimal.Decimal {
+	one := decimal.NewFromInt(1)
+	if side == Buy {
+		return best.Mul(one.Add(slippage)).RoundFloor(PricePlaces)
+	}
+	return best.Mul(one.Sub(slippage)).RoundCeil(PricePlaces)
+}
+
+// await syncs until the order is no longer open, cancelling it once the
+// timeout passes or ctx is done.
+func (m *Manager) await(ctx context.Context, ticket int64, opts MarketOptions) (Order, error) {
+	deadline := time.NewTimer(opts.Timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(opts.PollInterval)
+	defer poll.Stop()
+	var expired bool
+	for !expired {
+		select {
+		case <-ctx.Done():
+			expired = true
+		case <-deadline.C:
+			expired = true
+		case <-poll.C:
+		}
+		if err := m.Sync(); err != nil && !expired {
+			return m.mustOrder(ticket), err
+		}
+		if o := m.mustOrder(ticket); o.State != Open {
+			return o, nil
+		}
+	}
+	// Cancel settles fills up to the cancel and fails only if the order is
+	// still open.
+	if err := m.Cancel(ticket); err != nil {
+		return m.mustOrder(ticket), err
+	}
+	return m.mustOrder(ticket), ctx.Err()
+}
+
+func (m *Manager) mustOrder(ticket int64) Order {
+	o, _ := m.Order(ticket)
+	return o
+}
@@ -0,0 +1,80 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketable(t *testing.T) {
+	asks := []fyb.PriceAmount{{Price: d("50"), Amount: d("1")}, {Price: d("51"), Amount: d("5")}, {Price: d("60"), Amount: d("5")}}
+
+	price, qty, err := marketable(Buy, asks, d("2"), d("0"), d("0.05"))
+	require.NoError(t, err)
+	require.Equal(t, "51", price.String())
+	require.Equal(t, "2", qty.String())
+
+	price, qty, err = marketable(Buy, asks, d("0"), d("100"), d("0.05"))
+	require.NoError(t, err)
+	require.Equal(t, "51", price.String())
+	require.Equal(t, "1.980392", qty.String())
+
+	price, qty, err = marketable(Buy, asks, d("10"), d("0"), d("0.05"))
+	require.NoError(t, err)
+	require.Equal(t, "52.5", price.String(), "capped at the slippage bound")
+	require.Equal(t, "10", qty.String())
+
+	bids := []fyb.PriceAmount{{Price: d("99.99"), Amount: d("1")}}
+	price, _, err = marketable(Sell, bids, d("2"), d("0"), d("0.01"))
+	require.NoError(t, err)
+	require.Equal(t, "99", price.String(), "98.9901 rounded towards the best price")
+
+	_, _, err = marketable(Sell, nil, d("1"), d("0"), d("0.01"))
+	require.Equal(t, ErrNoLiquidity, err)
+}
+
+func TestMarketSell(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("100", "101")
+	m := NewManager(api)
+	go func() {
+		for len(x.Resting()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		x.Trade(1, "100", "0.6")
+	}()
+	o, err := m.MarketSell(context.Background(), d("0.6"), MarketOptions{MaxSlippage: d("0.01"), Timeout: 5 * time.Second, PollInterval: 50 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, Filled, o.State)
+	require.Equal(t, "100", o.Price.String())
+	require.Equal(t, "0.6", o.Filled.String())
+	require.Empty(t, x.Cancelled())
+}
+
+func TestMarketBuyCancelsRemainder(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("100", "101")
+	m := NewManager(api)
+	o, err := m.MarketBuy(context.Background(), d("1"), MarketOptions{Timeout: 300 * time.Millisecond, PollInterval: 100 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, Cancelled, o.State)
+	require.Equal(t, "101", o.Price.String())
+	require.Equal(t, []int64{o.Ticket}, x.Cancelled())
+}
+
+func TestMarketBuySizesFromBook(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetLevels(
+		[]fyb.PriceAmount{{Price: d("99"), Amount: d("1")}},
+		[]fyb.PriceAmount{{Price: d("100"), Amount: d("0.25")}, {Price: d("101"), Amount: d("1.75")}},
+	)
+	m := NewManager(api)
+	o, err := m.MarketBuyNotional(context.Background(), d("126"), MarketOptions{MaxSlippage: d("0.02"), Timeout: 100 * time.Millisecond, PollInterval: 50 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, "101", o.Price.String())
+	require.Equal(t, "1.25", o.Qty.String(), "0.25 at 100 and 1 at 101")
+}
@@ -0,0 +1,453 @@
+package execution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// TriggerKind is the condition of a Trigger.
+type TriggerKind string
+
+// Trigger kinds. Conditions are checked against the last traded price.
+const (
+	// StopLoss fires when the price reaches Price against the position:
+	// at or below Price for a sell, at or above for a buy.
+	StopLoss TriggerKind = "stop-loss"
+	// TakeProfit fires when the price reaches Price in favour of the
+	// position: at or above Price for a sell, at or below for a buy.
+	TakeProfit TriggerKind = "take-profit"
+	// TrailingStop fires when the price moves Trail away from the best
+	// price seen since the trigger was added: down from the high for a
+	// sell, up from the low for a buy.
+	TrailingStop TriggerKind = "trailing-stop"
+)
+
+// Trigger places an order when its condition is met. Triggers sharing an
+// OCO group are one-cancels-other: when one fires, the others are removed.
+type Trigger struct {
+	ID    string          `json:"id"`
+	Kind  TriggerKind     `json:"kind"`
+	Side  Side            `json:"side"` // side of the order placed
+	Qty   decimal.Decimal `json:"qty"`
+	Price decimal.Decimal `json:"price,omitempty"` // for StopLoss and TakeProfit
+	Trail decimal.Decimal `json:"trail,omitempty"` // for TrailingStop
+	// Extreme is the best price seen by a TrailingStop.
+	Extreme decimal.Decimal `json:"extreme,omitempty"`
+	// Slippage is how far beyond the best opposite price the order may be
+	// priced, as a fraction, e.g. 0.01 for 1%.
+	Slippage decimal.Decimal `json:"slippage"`
+	OCO      string          `json:"oco,omitempty"`
+	Created  time.Time       `json:"created"`
+}
+
+// check updates the trailing extreme and reports whether t fires at last.
+func (t *Trigger) check(last decimal.Decimal) bool {
+	switch t.Kind {
+	case StopLoss:
+		if t.Side == Sell {
+			return last.LessThanOrEqual(t.Price)
+		}
+		return last.GreaterThanOrEqual(t.Price)
+	case TakeProfit:
+		if t.Side == Sell {
+			return last.GreaterThanOrEqual(t.Price)
+		}
+		return last.LessThanOrEqual(t.Price)
+	case TrailingStop:
+		if t.Extreme.IsZero() || t.Side == Sell && last.GreaterThan(t.Extreme) || t.Side == Buy && last.LessThan(t.Extreme) {
+			t.Extreme = last
+		}
+		if t.Side == Sell {
+			return last.LessThanOrEqual(t.Extreme.Sub(t.Trail))
+		}
+		return last.GreaterThanOrEqual(t.Extreme.Add(t.Trail))
+	}
+	return false
+}
+
+func (t Trigger) validate() error {
+	switch {
+	case t.Side != Buy && t.Side != Sell:
+		return fmt.Errorf("trigger %s: invalid side %q", t.ID, t.Side)
+	case !t.Qty.IsPositive():
+		return fmt.Errorf("trigger %s: qty must be positive", t.ID)
+	case t.Kind == TrailingStop && !t.Trail.IsPositive():
+		return fmt.Errorf("trigger %s: trail must be positive", t.ID)
+	case (t.Kind == StopLoss || t.Kind == TakeProfit) && !t.Price.IsPositive():
+		return fmt.Errorf("trigger %s: price must be positive", t.ID)
+	case t.Kind != StopLoss && t.Kind != TakeProfit && t.Kind != TrailingStop:
+		return fmt.Errorf("trigger %s: unknown kind %q", t.ID, t.Kind)
+	}
+	return nil
+}
+
+// TriggerStore persists triggers.
+type TriggerStore interface {
+	Load() ([]Trigger, error)
+	Save(triggers []Trigger) error
+}
+
+// FileStore keeps triggers in a JSON file.
+type FileStore struct {
+	Path string
+}
+
+// Load implements TriggerStore. A missing file holds no triggers.
+func (s FileStore) Load() ([]Trigger, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var triggers []Trigger
+	if err := json.Unmarshal(data, &triggers); err != nil {
+		return nil, fmt.Errorf("%s: %v", s.Path, err)
+	}
+	return triggers, nil
+}
+
+// Save implements TriggerStore. The file is replaced atomically.
+func (s FileStore) Save(triggers []Trigger) error {
+	data, err := json.MarshalIndent(triggers, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// ErrNoLiquidity is returned when a trigger fires but the book has no
+// opposite side to price the order against.
+var ErrNoLiquidity = errors.New("order book has no opposite side")
+
+// TriggerEngine keeps triggers, checks them against the last price and
+// places their orders through a Manager when they fire. Every change is
+// saved to the store, so triggers survive restarts.
+type TriggerEngine struct {
+	m     *Manager
+	store TriggerStore
+
+	// OnFire, if set, is called with every fired trigger and its order.
+	OnFire func(t Trigger, o Order)
+	// OnError, if set, is called with errors from Watch.
+	OnError func(err error)
+
+	mu        sync.Mutex
+	triggers  []*Trigger
+	uncertain []uncertainFire
+	nextID    int
+}
+
+// uncertainFire is a fired trigger whose order may or may not have been
+// placed. Until the Manager tells, the trigger and its OCO group stay
+// removed.
+type uncertainFire struct {
+	trigger *Trigger
+	removed []*Trigger
+	price   decimal.Decimal
+	since   time.Time
+}
+
+// NewTriggerEngine returns a TriggerEngine with the triggers from store.
+func NewTriggerEngine(m *Manager, store TriggerStore) (*TriggerEngine, error) {
+	loaded, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	e := &TriggerEngine{m: m, store: store}
+	for i := range loaded {
+		e.triggers = append(e.triggers, &loaded[i])
+		if n, err := strconv.Atoi(loaded[i].ID); err == nil && n > e.nextID {
+			e.nextID = n
+		}
+	}
+	return e, nil
+}
+
+// Add adds a trigger and returns it with its ID set.
+func (e *TriggerEngine) Add(t Trigger) (Trigger, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t.ID == "" {
+		e.nextID++
+		t.ID = strconv.Itoa(e.nextID)
+	}
+	if err := t.validate(); err != nil {
+		return Trigger{}, err
+	}
+	for _, other := range e.triggers {
+		if other.ID == t.ID {
+			return Trigger{}, fmt.Errorf("trigger %s already exists", t.ID)
+		}
+	}
+	if t.Created.IsZero() {
+		t.Created = e.m.now()
+	}
+	e.triggers = append(e.triggers, &t)
+	return t, e.save()
+}
+
+// Remove removes a trigger.
+func (e *TriggerEngine) Remove(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.remove(func(t *Trigger) bool { return t.ID == id })
+	return e.save()
+}
+
+// Triggers returns the active triggers.
+func (e *TriggerEngine) Triggers() []Trigger {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	res := make([]Trigger, 0, len(e.triggers))
+	for _, t := range e.triggers {
+		res = append(res, *t)
+	}
+	return res
+}
+
+// Update checks all triggers against the last traded price and fires
+// those whose condition is met. A trigger whose order cannot be placed
+// stays active and fires again on the next update. If the order may have
+// reached FYB, e.g. on a timeout, the next update first looks for it and
+// puts the trigger back only if it was not placed.
+func (e *TriggerEngine) Update(last decimal.Decimal) error {
+	return e.update([]decimal.Decimal{last})
+}
+
+// update checks all triggers against prices, oldest first, and fires
+// those whose condition is met by any of them.
+//
+// A fired trigger and its OCO group are removed and saved before the
+// order is placed, so a trigger never fires twice across a restart. If
+// the order cannot be placed, they are put back. If it may have been
+// placed, they are put back only once the order is known to be missing.
+func (e *TriggerEngine) update(prices []decimal.Decimal) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	changed, errs := e.resolve()
+	var fired []*Trigger
+	for _, t := range e.triggers {
+		extreme := t.Extreme
+		for _, last := range prices {
+			if t.check(last) {
+				fired = append(fired, t)
+				break
+			}
+		}
+		changed = changed || !extreme.Equal(t.Extreme)
+	}
+	sort.SliceStable(fired, func(i, j int) bool { return fired[i].Created.Before(fired[j].Created) })
+
+	var book *fyb.OrderBook
+	for _, t := range fired {
+		if !e.active(t) {
+			// An earlier trigger of the same OCO group fired.
+			continue
+		}
+		if book == nil {
+			b, err := e.m.api.GetOrderBook()
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+			book = &b
+		}
+		price, err := t.price(*book)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trigger %s: %v", t.ID, err))
+			continue
+		}
+
+		before := append([]*Trigger(nil), e.triggers...)
+		e.remove(func(other *Trigger) bool { return other == t || t.OCO != "" && other.OCO == t.OCO })
+		if err := e.save(); err != nil {
+			e.triggers = before
+			errs = append(errs, fmt.Errorf("trigger %s: not fired: %v", t.ID, err))
+			break
+		}
+		changed = false
+		since := e.m.now()
+		o, err := e.m.Place(t.Side, price, t.Qty)
+		var uncertain *UncertainPlaceError
+		if errors.As(err, &uncertain) {
+			e.uncertain = append(e.uncertain, uncertainFire{trigger: t, removed: removedFrom(before, e.triggers), price: price, since: since})
+			errs = append(errs, fmt.Errorf("trigger %s: %v", t.ID, err))
+			continue
+		}
+		if err != nil {
+			e.triggers = before
+			changed = true
+			errs = append(errs, fmt.Errorf("trigger %s: %v", t.ID, err))
+			continue
+		}
+		if e.OnFire != nil {
+			e.OnFire(*t, o)
+		}
+	}
+	if changed {
+		if err := e.save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// resolve asks the Manager whether the orders of uncertain fires were
+// placed. Fires whose order is found are reported to OnFire; the triggers
+// of those whose order is missing are put back. Called with e.mu held.
+func (e *TriggerEngine) resolve() (changed bool, errs []error) {
+	var unresolved []uncertainFire
+	for _, u := range e.uncertain {
+		o, ok, err := e.m.Placed(u.trigger.Side, u.price, u.trigger.Qty, u.since)
+		switch {
+		case err != nil:
+			unresolved = append(unresolved, u)
+			errs = append(errs, fmt.Errorf("trigger %s: %v", u.trigger.ID, err))
+		case ok:
+			if e.OnFire != nil {
+				e.OnFire(*u.trigger, o)
+			}
+		default:
+			e.triggers = append(e.triggers, u.removed...)
+			changed = true
+		}
+	}
+	e.uncertain = unresolved
+	return changed, errs
+}
+
+// removedFrom returns the triggers of before that are not in after.
+func removedFrom(before, after []*Trigger) []*Trigger {
+	var removed []*Trigger
+	for _, t := range before {
+		kept := false
+		for _, other := range after {
+			kept = kept || other == t
+		}
+		if !kept {
+			removed = append(removed, t)
+		}
+	}
+	return removed
+}
+
+// price returns the price of the order of t, priced to cross the book: at
+// the best opposite price moved by the allowed slippage.
+func (t Trigger) price(book fyb.OrderBook) (decimal.Decimal, error) {
+	levels := book.Asks
+	if t.Side == Sell {
+		levels = book.Bids
+	}
+	if len(levels) == 0 {
+		return decimal.Zero, ErrNoLiquidity
+	}
+	return slippageBound(t.Side, levels[0].Price, t.Slippage), nil
+}
+
+// active reports whether t is still one of the engine's triggers. Called
+// with e.mu held.
+func (e *TriggerEngine) active(t *Trigger) bool {
+	for _, other := range e.triggers {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
+
+// remove drops the triggers matching drop. Called with e.mu held.
+func (e *TriggerEngine) remove(drop func(t *Trigger) bool) {
+	kept := e.triggers[:0]
+	for _, t := range e.triggers {
+		if !drop(t) {
+			kept = append(kept, t)
+		}
+	}
+	e.triggers = kept
+}
+
+// save writes the triggers to the store. Called with e.mu held.
+func (e *TriggerEngine) save() error {
+	triggers := make([]Trigger, 0, len(e.triggers))
+	for _, t := range e.triggers {
+		triggers = append(triggers, *t)
+	}
+	return e.store.Save(triggers)
+}
+
+func (e *TriggerEngine) error(err error) {
+	if e.OnError != nil {
+		e.OnError(err)
+	}
+}
+
+// Watch updates the engine with the price of every new trade from tracker
+// and with the ticker's last price every interval, until the returned
+// function is called. The tracker must be started separately.
+func (e *TriggerEngine) Watch(tracker *fyb.Tracker, interval time.Duration) (stop func()) {
+	trades, unsub := tracker.SubscribeTrades()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer unsub()
+		poll := time.NewTicker(interval)
+		defer poll.Stop()
+		for {
+			var err error
+			select {
+			case <-quit:
+				return
+			case batch := <-trades:
+				if len(batch) > 0 {
+					prices := make([]decimal.Decimal, len(batch))
+					for i, t := range batch {
+						prices[i] = t.Price
+					}
+					err = e.update(prices)
+				}
+			case <-poll.C:
+				var ticker fyb.Ticker
+				if ticker, err = e.m.api.GetTicker(); err == nil {
+					err = e.Update(ticker.Last)
+				}
+			}
+			if err != nil {
+				e.error(err)
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
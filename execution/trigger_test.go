@@ -0,0 +1,169 @@
+package execution
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerOCO(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("97", "99")
+	store := FileStore{Path: filepath.Join(t.TempDir(), "triggers.json")}
+	e, err := NewTriggerEngine(NewManager(api), store)
+	require.NoError(t, err)
+
+	var fired []Trigger
+	e.OnFire = func(tr Trigger, o Order) { fired = append(fired, tr) }
+	stop, err := e.Add(Trigger{Kind: StopLoss, Side: Sell, Qty: d("1"), Price: d("98"), Slippage: d("0.01"), OCO: "exit"})
+	require.NoError(t, err)
+	_, err = e.Add(Trigger{Kind: TakeProfit, Side: Sell, Qty: d("1"), Price: d("120"), Slippage: d("0.01"), OCO: "exit"})
+	require.NoError(t, err)
+	_, err = e.Add(Trigger{Kind: StopLoss, Side: Sell, Qty: d("0"), Price: d("98")})
+	require.Error(t, err)
+
+	require.NoError(t, e.Update(d("102")))
+	require.Empty(t, x.Resting())
+
+	require.NoError(t, e.Update(d("98")))
+	require.Equal(t, []Trigger{stop}, fired)
+	resting := x.Resting()
+	require.Len(t, resting, 1)
+	for _, o := range resting {
+		require.Equal(t, "S", o.Side)
+		require.Equal(t, "96.03", o.Price.String(), "best bid less 1%")
+	}
+	require.Empty(t, e.Triggers(), "take-profit cancelled with its OCO group")
+
+	saved, err := store.Load()
+	require.NoError(t, err)
+	require.Empty(t, saved)
+}
+
+func TestTrailingStopSurvivesRestart(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("104", "106")
+	store := FileStore{Path: filepath.Join(t.TempDir(), "triggers.json")}
+	e, err := NewTriggerEngine(NewManager(api), store)
+	require.NoError(t, err)
+	_, err = e.Add(Trigger{Kind: TrailingStop, Side: Sell, Qty: d("0.5"), Trail: d("5")})
+	require.NoError(t, err)
+	require.NoError(t, e.Update(d("100")))
+	require.NoError(t, e.Update(d("110")))
+	require.NoError(t, e.Update(d("107")))
+
+	e, err = NewTriggerEngine(NewManager(api), store)
+	require.NoError(t, err)
+	triggers := e.Triggers()
+	require.Len(t, triggers, 1)
+	require.Equal(t, "110", triggers[0].Extreme.String())
+
+	require.NoError(t, e.Update(d("106")))
+	require.Empty(t, x.Resting())
+	require.NoError(t, e.Update(d("105")))
+	require.Empty(t, e.Triggers())
+	for _, o := range x.Resting() {
+		require.Equal(t, "104", o.Price.String(), "no slippage allowed")
+	}
+	require.Len(t, x.Resting(), 1)
+
+	next, err := e.Add(Trigger{Kind: StopLoss, Side: Buy, Qty: d("1"), Price: d("120")})
+	require.NoError(t, err)
+	require.Equal(t, "2", next.ID, "IDs continue after the loaded ones")
+}
+
+// flakyStore is a FileStore whose saves fail while fail is set.
+type flakyStore struct {
+	FileStore
+	fail bool
+}
+
+func (s *flakyStore) Save(triggers []Trigger) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.FileStore.Save(triggers)
+}
+
+func TestTriggerSavedBeforeFiring(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("98.99", "100")
+	store := &flakyStore{FileStore: FileStore{Path: filepath.Join(t.TempDir(), "triggers.json")}}
+	e, err := NewTriggerEngine(NewManager(api), store)
+	require.NoError(t, err)
+	_, err = e.Add(Trigger{Kind: StopLoss, Side: Sell, Qty: d("1"), Price: d("99"), Slippage: d("0.01")})
+	require.NoError(t, err)
+
+	store.fail = true
+	require.EqualError(t, e.Update(d("98")), "trigger 1: not fired: disk full")
+	require.Empty(t, x.Resting(), "no order unless the trigger is saved as fired")
+	require.Len(t, e.Triggers(), 1)
+
+	store.fail = false
+	require.NoError(t, e.Update(d("98")))
+	require.Empty(t, e.Triggers())
+	for _, o := range x.Resting() {
+		require.Equal(t, "98.01", o.Price.String(), "98.0001 rounded towards the best bid")
+	}
+	saved, err := store.Load()
+	require.NoError(t, err)
+	require.Empty(t, saved)
+}
+
+func TestTrailingStopSeesWholeBatch(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("104", "106")
+	e, err := NewTriggerEngine(NewManager(api), FileStore{Path: filepath.Join(t.TempDir(), "triggers.json")})
+	require.NoError(t, err)
+	_, err = e.Add(Trigger{Kind: TrailingStop, Side: Sell, Qty: d("0.5"), Trail: d("5")})
+	require.NoError(t, err)
+	require.NoError(t, e.Update(d("100")))
+
+	// The high of 110 inside the batch moves the stop to 105.
+	require.NoError(t, e.update([]decimal.Decimal{d("110"), d("104")}))
+	require.Empty(t, e.Triggers())
+	require.Len(t, x.Resting(), 1)
+}
+
+func TestTriggerUncertainPlace(t *testing.T) {
+	x, api := exectest.New(t)
+	x.SetBook("98.99", "100")
+	e, err := NewTriggerEngine(NewManager(api), FileStore{Path: filepath.Join(t.TempDir(), "triggers.json")})
+	require.NoError(t, err)
+	var fired []Order
+	e.OnFire = func(tr Trigger, o Order) { fired = append(fired, o) }
+	_, err = e.Add(Trigger{Kind: StopLoss, Side: Sell, Qty: d("1"), Price: d("99"), Slippage: d("0.01")})
+	require.NoError(t, err)
+
+	// The order is placed but the response is lost: it is found, not
+	// placed again.
+	x.FailPlaces(1)
+	require.Error(t, e.Update(d("98")))
+	require.Empty(t, e.Triggers())
+	require.Len(t, x.Resting(), 1)
+	require.NoError(t, e.Update(d("98")))
+	require.Len(t, x.Resting(), 1)
+	require.Len(t, fired, 1)
+	_, ok := x.Resting()[fired[0].Ticket]
+	require.True(t, ok)
+
+	// An order that did not make it is fired again.
+	_, err = e.Add(Trigger{Kind: StopLoss, Side: Sell, Qty: d("2"), Price: d("99"), Slippage: d("0.01")})
+	require.NoError(t, err)
+	x.FailPlaces(1)
+	require.Error(t, e.Update(d("98")))
+	for ticket, o := range x.Resting() {
+		if o.Qty.Equal(d("2")) {
+			_, err := api.CancelPendingOrder(ticket)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, e.Update(d("98")))
+	require.Len(t, fired, 2)
+	require.Len(t, x.Resting(), 2)
+	require.Empty(t, e.Triggers())
+}
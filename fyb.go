@@ -3,7 +3,7 @@ package fyb
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -19,11 +19,6 @@ const (
 	APIBaseURLForTest = "https://private-a9161-fyb.apiary-proxy.com/api/SGD"
 )
 
-func init() {
-	log.SetFlags(log.Lshortfile)
-
-}
-
 // New returns an instantiated fyb struct
 func New(apibaseurl, apiKey, apiSecret string) *Fyb {
 	client := NewClient(apibaseurl, apiKey, apiSecret)
@@ -41,9 +36,52 @@ type Fyb struct {
 	client *Client
 }
 
+// Currency returns the fiat currency of the market, the last element of
+// the API base URL, e.g. "SGD" or "SEK".
+func (b *Fyb) Currency() string {
+	u := b.client.apiBaseUrl
+	return strings.ToUpper(u[strings.LastIndex(u, "/")+1:])
+}
+
+// SetLogger sets the logger used by the underlying client.
+// *slog.Logger can be passed directly.
+func (b *Fyb) SetLogger(logger Logger) {
+	b.client.SetLogger(logger)
+}
+
+// SetDebug enables redacted request/response dumps at debug level.
+func (b *Fyb) SetDebug(debug bool) {
+	b.client.SetDebug(debug)
+}
+
+// SetMetrics sets the metrics collector used by the underlying client.
+func (b *Fyb) SetMetrics(metrics Metrics) {
+	b.client.SetMetrics(metrics)
+}
+
+// SetMaxBodySize sets the largest response body the underlying client reads.
+func (b *Fyb) SetMaxBodySize(n int64) {
+	b.client.SetMaxBodySize(n)
+}
+
+// SetBreaker installs a circuit breaker in the underlying client.
+func (b *Fyb) SetBreaker(breaker *Breaker) {
+	b.client.SetBreaker(breaker)
+}
+
+// SetTransport replaces the HTTP transport of the underlying client.
+func (b *Fyb) SetTransport(transport http.RoundTripper) {
+	b.client.SetTransport(transport)
+}
+
+// Use appends middleware to the underlying client.
+func (b *Fyb) Use(middleware ...Middleware) {
+	b.client.Use(middleware...)
+}
+
 // GetOrderBook ..
-func (b *Fyb) GetOrderBook() (orderbook OrderBook, r []byte, err error) {
-	r, err = b.client.do("GET", "orderbook.json", nil, false)
+func (b *Fyb) GetOrderBook() (orderbook OrderBook, err error) {
+	r, err := b.client.do("GET", "orderbook.json", nil, false)
 	if err != nil {
 		//log.Print(err)
 		return
@@ -85,8 +123,8 @@ func (b *Fyb) GetOrderBook() (orderbook OrderBook, r []byte, err error) {
 }
 
 // GetTicker ...
-func (b *Fyb) GetTicker() (ticker Ticker, r []byte, err error) {
-	r, err = b.client.do("GET", "tickerdetailed.json", nil, false)
+func (b *Fyb) GetTicker() (ticker Ticker, err error) {
+	r, err := b.client.do("GET", "tickerdetailed.json", nil, false)
 	if err != nil {
 		return
 	}
@@ -99,8 +137,8 @@ func (b *Fyb) GetTicker() (ticker Ticker, r []byte, err error) {
 // GetTradeHistory ...
 // tid ()= Trade ID) to begin trade history from.
 // You should cache trade history and query only new trades by passing in last known trade id
-func (b *Fyb) GetTradeHistory(tid int64) (trades Trades, r []byte, err error) {
-	r, err = b.client.do("GET", fmt.Sprintf("trades.json?since=%d", tid), nil, false)
+func (b *Fyb) GetTradeHistory(tid int64) (trades Trades, err error) {
+	r, err := b.client.do("GET", fmt.Sprintf("trades.json?since=%d", tid), nil, false)
 	if err != nil {
 		return
 	}
@@ -111,8 +149,8 @@ func (b *Fyb) GetTradeHistory(tid int64) (trades Trades, r []byte, err error) {
 }
 
 // APITokenTest private API
-func (b *Fyb) APITokenTest() (res TestResponse, r []byte, err error) {
-	r, err = b.client.do("POST", fmt.Sprintf("test"), nil, true)
+func (b *Fyb) APITokenTest() (res TestResponse, err error) {
+	r, err := b.client.do("POST", fmt.Sprintf("test"), nil, true)
 	if err != nil {
 		return
 	}
@@ -124,9 +162,9 @@ func (b *Fyb) APITokenTest() (res TestResponse, r []byte, err error) {
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "test", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 		return
 	}
@@ -134,8 +172,8 @@ func (b *Fyb) APITokenTest() (res TestResponse, r []byte, err error) {
 }
 
 // GetAccountInfo ..
-func (b *Fyb) GetAccountInfo() (res AccountInfoResponse, r []byte, err error) {
-	r, err = b.client.do("POST", fmt.Sprintf("getaccinfo"), nil, true)
+func (b *Fyb) GetAccountInfo() (res AccountInfoResponse, err error) {
+	r, err := b.client.do("POST", fmt.Sprintf("getaccinfo"), nil, true)
 	if err != nil {
 		return
 	}
@@ -150,9 +188,9 @@ func (b *Fyb) GetAccountInfo() (res AccountInfoResponse, r []byte, err error) {
 			return
 		}
 
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "getaccinfo", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 
 		return
@@ -162,8 +200,8 @@ func (b *Fyb) GetAccountInfo() (res AccountInfoResponse, r []byte, err error) {
 }
 
 // GetPendingOrders ..
-func (b *Fyb) GetPendingOrders() (res PendingOrderResponse, r []byte, err error) {
-	r, err = b.client.do("POST", fmt.Sprintf("getpendingorders"), nil, true)
+func (b *Fyb) GetPendingOrders() (res PendingOrderResponse, err error) {
+	r, err := b.client.do("POST", fmt.Sprintf("getpendingorders"), nil, true)
 	if err != nil {
 		return
 	}
@@ -175,9 +213,9 @@ func (b *Fyb) GetPendingOrders() (res PendingOrderResponse, r []byte, err error)
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "getpendingorders", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 		return
 	}
@@ -186,10 +224,10 @@ func (b *Fyb) GetPendingOrders() (res PendingOrderResponse, r []byte, err error)
 
 // GetOrderHistory ..
 // limit int64, Number of Order History Items to return : Number
-func (b *Fyb) GetOrderHistory(limit int64) (res OrderHistoryResponse, r []byte, err error) {
+func (b *Fyb) GetOrderHistory(limit int64) (res OrderHistoryResponse, err error) {
 	payload := map[string]string{}
 	payload["limit"] = fmt.Sprintf("%d", limit)
-	r, err = b.client.do("POST", fmt.Sprintf("getorderhistory"), payload, true)
+	r, err := b.client.do("POST", fmt.Sprintf("getorderhistory"), payload, true)
 	if err != nil {
 
 		return
@@ -204,9 +242,9 @@ func (b *Fyb) GetOrderHistory(limit int64) (res OrderHistoryResponse, r []byte,
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "getorderhistory", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 		return
 	}
@@ -217,11 +255,11 @@ func (b *Fyb) GetOrderHistory(limit int64) (res OrderHistoryResponse, r []byte,
 
 // CancelPendingOrder ..
 // orderNo, Ticket Number of Pending Order to cancel. :number
-func (b *Fyb) CancelPendingOrder(orderNo int64) (res CancelPendingOrderResponse, r []byte, err error) {
+func (b *Fyb) CancelPendingOrder(orderNo int64) (res CancelPendingOrderResponse, err error) {
 	payload := map[string]string{}
 	payload["orderNo"] = fmt.Sprintf("%d", orderNo)
 
-	r, err = b.client.do("POST", fmt.Sprintf("cancelpendingorder"), payload, true)
+	r, err := b.client.do("POST", fmt.Sprintf("cancelpendingorder"), payload, true)
 	if err != nil {
 		return
 	}
@@ -234,9 +272,9 @@ func (b *Fyb) CancelPendingOrder(orderNo int64) (res CancelPendingOrderResponse,
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "cancelpendingorder", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 		return
 	}
@@ -248,7 +286,7 @@ func (b *Fyb) CancelPendingOrder(orderNo int64) (res CancelPendingOrderResponse,
 // qty float64, Quantity of bitcoins, Number
 // price , Price to place order at , Number
 // type , Whether it is a buy or sell order. Must be either 'B' or 'S' only. ,Char
-func (b *Fyb) PlaceOrder(orderType string, price, qty float64) (res PlaceOrderResponse, r []byte, err error) {
+func (b *Fyb) PlaceOrder(orderType string, price, qty float64) (res PlaceOrderResponse, err error) {
 
 	orderType = strings.ToUpper(orderType)
 	payload := map[string]string{}
@@ -263,7 +301,7 @@ func (b *Fyb) PlaceOrder(orderType string, price, qty float64) (res PlaceOrderRe
 	payload["price"] = fmt.Sprintf("%f", price)
 	payload["qty"] = fmt.Sprintf("%f", qty)
 
-	r, err = b.client.do("POST", fmt.Sprintf("placeorder"), payload, true)
+	r, err := b.client.do("POST", fmt.Sprintf("placeorder"), payload, true)
 	if err != nil {
 		return
 	}
@@ -275,9 +313,9 @@ func (b *Fyb) PlaceOrder(orderType string, price, qty float64) (res PlaceOrderRe
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "placeorder", "body", redactBody(r))
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 		return
 	}
@@ -288,7 +326,7 @@ func (b *Fyb) PlaceOrder(orderType string, price, qty float64) (res PlaceOrderRe
 // amount Amount of bitcoins/dollars to withdraw: Number
 // destination Bitcoin address to withdraw to, leave blank for XFERS: String
 // type BTC/XFERS (XFERS only for FYB-SG) // Char
-func (b *Fyb) Withdraw(amount float64, destination string, destinationType string) (res WithdrawResponse, r []byte, err error) {
+func (b *Fyb) Withdraw(amount float64, destination string, destinationType string) (res WithdrawResponse, err error) {
 	destinationType = strings.ToUpper(destinationType)
 	payload := map[string]string{}
 	payload["destination"] = strings.Trim(destination, "\r\n ")
@@ -303,11 +341,10 @@ func (b *Fyb) Withdraw(amount float64, destination string, destinationType strin
 		return
 	}
 
-	r, err = b.client.do("POST", fmt.Sprintf("withdraw"), nil, true)
+	r, err := b.client.do("POST", fmt.Sprintf("withdraw"), payload, true)
 	if err != nil {
 		return
 	}
-	//log.Printf("body=%s", string(r))
 	if err = json.Unmarshal(r, &res); err != nil {
 
 		kperr := KeyPermissionErrorResponse{}
@@ -317,10 +354,10 @@ func (b *Fyb) Withdraw(amount float64, destination string, destinationType strin
 			err = fmt.Errorf(kperr.Error)
 			return
 		}
-		log.Printf("body=%s", string(r))
+		b.client.logger.Warn("unexpected response", "endpoint", "withdraw", "body", redactBody(r))
 
 		res.Error = 1
-		err = fmt.Errorf("%s: body=%s", err.Error(), string(r))
+		err = fmt.Errorf("%s: body=%s", err.Error(), redactBody(r))
 		res.Msg = err.Error()
 
 		return
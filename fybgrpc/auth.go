@@ -0,0 +1,67 @@
+package fybgrpc
+
+import (
+	"context"
+
+	"github.com/rakd/go-fyb/fybgrpc/fybpb"
+	"github.com/rakd/go-fyb/gateway"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// scopes maps every RPC to the gateway scope it requires.
+var scopes = map[string]gateway.Scope{
+	fybpb.FYB_GetTicker_FullMethodName:        gateway.ScopeRead,
+	fybpb.FYB_GetOrderBook_FullMethodName:     gateway.ScopeRead,
+	fybpb.FYB_GetTrades_FullMethodName:        gateway.ScopeRead,
+	fybpb.FYB_GetAccount_FullMethodName:       gateway.ScopeRead,
+	fybpb.FYB_GetPendingOrders_FullMethodName: gateway.ScopeRead,
+	fybpb.FYB_GetOrderHistory_FullMethodName:  gateway.ScopeRead,
+	fybpb.FYB_StreamTrades_FullMethodName:     gateway.ScopeRead,
+	fybpb.FYB_StreamOrderBook_FullMethodName:  gateway.ScopeRead,
+	fybpb.FYB_PlaceOrder_FullMethodName:       gateway.ScopeTrade,
+	fybpb.FYB_CancelOrder_FullMethodName:      gateway.ScopeTrade,
+	fybpb.FYB_Withdraw_FullMethodName:         gateway.ScopeWithdraw,
+}
+
+// Auth returns server options that authenticate every call with the same
+// callers, bearer tokens and scopes as the REST gateway. Clients send the
+// token as "authorization: Bearer <token>" metadata.
+func Auth(callers []gateway.Caller) ([]grpc.ServerOption, error) {
+	if err := gateway.ValidateCallers(callers); err != nil {
+		return nil, err
+	}
+	check := func(ctx context.Context, method string) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var caller *gateway.Caller
+		for _, auth := range md.Get("authorization") {
+			if caller = gateway.Lookup(callers, auth); caller != nil {
+				break
+			}
+		}
+		if caller == nil {
+			return status.Error(codes.Unauthenticated, "unauthorized")
+		}
+		scope, ok := scopes[method]
+		if !ok || !caller.Has(scope) {
+			return status.Errorf(codes.PermissionDenied, "scope %q required", scope)
+		}
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}, nil
+}
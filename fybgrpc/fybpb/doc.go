@@ -0,0 +1,6 @@
+// Package fybpb contains the protobuf messages and gRPC stubs for the FYB
+// service defined in fyb.proto. Clients in other languages can generate
+// their stubs from the same file.
+package fybpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fyb.proto
@@ -0,0 +1,1501 @@
+// FYB exchange API as a gRPC service. Decimal values are strings to keep
+// full precision; times are Unix seconds as returned by FYB.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: fyb.proto
+
+package fybpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Side int32
+
+const (
+	Side_SIDE_UNSPECIFIED Side = 0
+	Side_SIDE_BUY         Side = 1
+	Side_SIDE_SELL        Side = 2
+)
+
+// Enum value maps for Side.
+var (
+	Side_name = map[int32]string{
+		0: "SIDE_UNSPECIFIED",
+		1: "SIDE_BUY",
+		2: "SIDE_SELL",
+	}
+	Side_value = map[string]int32{
+		"SIDE_UNSPECIFIED": 0,
+		"SIDE_BUY":         1,
+		"SIDE_SELL":        2,
+	}
+)
+
+func (x Side) Enum() *Side {
+	p := new(Side)
+	*p = x
+	return p
+}
+
+func (x Side) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Side) Descriptor() protoreflect.EnumDescriptor {
+	return file_fyb_proto_enumTypes[0].Descriptor()
+}
+
+func (Side) Type() protoreflect.EnumType {
+	return &file_fyb_proto_enumTypes[0]
+}
+
+func (x Side) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Side.Descriptor instead.
+func (Side) EnumDescriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{0}
+}
+
+type GetTickerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTickerRequest) Reset() {
+	*x = GetTickerRequest{}
+	mi := &file_fyb_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTickerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTickerRequest) ProtoMessage() {}
+
+func (x *GetTickerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTickerRequest.ProtoReflect.Descriptor instead.
+func (*GetTickerRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{0}
+}
+
+type Ticker struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ask           string                 `protobuf:"bytes,1,opt,name=ask,proto3" json:"ask,omitempty"`
+	Bid           string                 `protobuf:"bytes,2,opt,name=bid,proto3" json:"bid,omitempty"`
+	Last          string                 `protobuf:"bytes,3,opt,name=last,proto3" json:"last,omitempty"`
+	Vol           string                 `protobuf:"bytes,4,opt,name=vol,proto3" json:"vol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ticker) Reset() {
+	*x = Ticker{}
+	mi := &file_fyb_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ticker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ticker) ProtoMessage() {}
+
+func (x *Ticker) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ticker.ProtoReflect.Descriptor instead.
+func (*Ticker) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ticker) GetAsk() string {
+	if x != nil {
+		return x.Ask
+	}
+	return ""
+}
+
+func (x *Ticker) GetBid() string {
+	if x != nil {
+		return x.Bid
+	}
+	return ""
+}
+
+func (x *Ticker) GetLast() string {
+	if x != nil {
+		return x.Last
+	}
+	return ""
+}
+
+func (x *Ticker) GetVol() string {
+	if x != nil {
+		return x.Vol
+	}
+	return ""
+}
+
+type GetOrderBookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Levels per side, 0 for all.
+	Depth         int32 `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookRequest) Reset() {
+	*x = GetOrderBookRequest{}
+	mi := &file_fyb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookRequest) ProtoMessage() {}
+
+func (x *GetOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetOrderBookRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type Level struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         string                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	Amount        string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Level) Reset() {
+	*x = Level{}
+	mi := &file_fyb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Level) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Level) ProtoMessage() {}
+
+func (x *Level) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Level.ProtoReflect.Descriptor instead.
+func (*Level) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Level) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Level) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type OrderBook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bids          []*Level               `protobuf:"bytes,1,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*Level               `protobuf:"bytes,2,rep,name=asks,proto3" json:"asks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBook) Reset() {
+	*x = OrderBook{}
+	mi := &file_fyb_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBook) ProtoMessage() {}
+
+func (x *OrderBook) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBook.ProtoReflect.Descriptor instead.
+func (*OrderBook) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderBook) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *OrderBook) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+type GetTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceTid      int64                  `protobuf:"varint,1,opt,name=since_tid,json=sinceTid,proto3" json:"since_tid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradesRequest) Reset() {
+	*x = GetTradesRequest{}
+	mi := &file_fyb_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradesRequest) ProtoMessage() {}
+
+func (x *GetTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradesRequest.ProtoReflect.Descriptor instead.
+func (*GetTradesRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTradesRequest) GetSinceTid() int64 {
+	if x != nil {
+		return x.SinceTid
+	}
+	return 0
+}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tid           int64                  `protobuf:"varint,1,opt,name=tid,proto3" json:"tid,omitempty"`
+	Date          int64                  `protobuf:"varint,2,opt,name=date,proto3" json:"date,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	Amount        string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_fyb_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Trade) GetTid() int64 {
+	if x != nil {
+		return x.Tid
+	}
+	return 0
+}
+
+func (x *Trade) GetDate() int64 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+func (x *Trade) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Trade) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type GetTradesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*Trade               `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradesResponse) Reset() {
+	*x = GetTradesResponse{}
+	mi := &file_fyb_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradesResponse) ProtoMessage() {}
+
+func (x *GetTradesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradesResponse.ProtoReflect.Descriptor instead.
+func (*GetTradesResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTradesResponse) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+type GetAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountRequest) Reset() {
+	*x = GetAccountRequest{}
+	mi := &file_fyb_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountRequest) ProtoMessage() {}
+
+func (x *GetAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{8}
+}
+
+type Account struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AccNo             int64                  `protobuf:"varint,1,opt,name=acc_no,json=accNo,proto3" json:"acc_no,omitempty"`
+	BtcBalance        string                 `protobuf:"bytes,2,opt,name=btc_balance,json=btcBalance,proto3" json:"btc_balance,omitempty"`
+	FiatBalance       string                 `protobuf:"bytes,3,opt,name=fiat_balance,json=fiatBalance,proto3" json:"fiat_balance,omitempty"`
+	BtcDepositAddress string                 `protobuf:"bytes,4,opt,name=btc_deposit_address,json=btcDepositAddress,proto3" json:"btc_deposit_address,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_fyb_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Account) GetAccNo() int64 {
+	if x != nil {
+		return x.AccNo
+	}
+	return 0
+}
+
+func (x *Account) GetBtcBalance() string {
+	if x != nil {
+		return x.BtcBalance
+	}
+	return ""
+}
+
+func (x *Account) GetFiatBalance() string {
+	if x != nil {
+		return x.FiatBalance
+	}
+	return ""
+}
+
+func (x *Account) GetBtcDepositAddress() string {
+	if x != nil {
+		return x.BtcDepositAddress
+	}
+	return ""
+}
+
+type GetPendingOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPendingOrdersRequest) Reset() {
+	*x = GetPendingOrdersRequest{}
+	mi := &file_fyb_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPendingOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPendingOrdersRequest) ProtoMessage() {}
+
+func (x *GetPendingOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPendingOrdersRequest.ProtoReflect.Descriptor instead.
+func (*GetPendingOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{10}
+}
+
+type PendingOrder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        int64                  `protobuf:"varint,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Side          Side                   `protobuf:"varint,2,opt,name=side,proto3,enum=fyb.v1.Side" json:"side,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           string                 `protobuf:"bytes,4,opt,name=qty,proto3" json:"qty,omitempty"`
+	Date          int64                  `protobuf:"varint,5,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PendingOrder) Reset() {
+	*x = PendingOrder{}
+	mi := &file_fyb_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PendingOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingOrder) ProtoMessage() {}
+
+func (x *PendingOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingOrder.ProtoReflect.Descriptor instead.
+func (*PendingOrder) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PendingOrder) GetTicket() int64 {
+	if x != nil {
+		return x.Ticket
+	}
+	return 0
+}
+
+func (x *PendingOrder) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *PendingOrder) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *PendingOrder) GetQty() string {
+	if x != nil {
+		return x.Qty
+	}
+	return ""
+}
+
+func (x *PendingOrder) GetDate() int64 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+type GetPendingOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*PendingOrder        `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPendingOrdersResponse) Reset() {
+	*x = GetPendingOrdersResponse{}
+	mi := &file_fyb_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPendingOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPendingOrdersResponse) ProtoMessage() {}
+
+func (x *GetPendingOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPendingOrdersResponse.ProtoReflect.Descriptor instead.
+func (*GetPendingOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetPendingOrdersResponse) GetOrders() []*PendingOrder {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type GetOrderHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryRequest) Reset() {
+	*x = GetOrderHistoryRequest{}
+	mi := &file_fyb_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryRequest) ProtoMessage() {}
+
+func (x *GetOrderHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetOrderHistoryRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type HistoryOrder struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Ticket       int64                  `protobuf:"varint,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	Price        string                 `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	Qty          string                 `protobuf:"bytes,3,opt,name=qty,proto3" json:"qty,omitempty"`
+	DateCreated  int64                  `protobuf:"varint,4,opt,name=date_created,json=dateCreated,proto3" json:"date_created,omitempty"`
+	DateExecuted int64                  `protobuf:"varint,5,opt,name=date_executed,json=dateExecuted,proto3" json:"date_executed,omitempty"`
+	Side         Side                   `protobuf:"varint,6,opt,name=side,proto3,enum=fyb.v1.Side" json:"side,omitempty"`
+	// FYB order status, e.g. "A" or "F" for executed.
+	Status        string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryOrder) Reset() {
+	*x = HistoryOrder{}
+	mi := &file_fyb_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryOrder) ProtoMessage() {}
+
+func (x *HistoryOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryOrder.ProtoReflect.Descriptor instead.
+func (*HistoryOrder) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HistoryOrder) GetTicket() int64 {
+	if x != nil {
+		return x.Ticket
+	}
+	return 0
+}
+
+func (x *HistoryOrder) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetQty() string {
+	if x != nil {
+		return x.Qty
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetDateCreated() int64 {
+	if x != nil {
+		return x.DateCreated
+	}
+	return 0
+}
+
+func (x *HistoryOrder) GetDateExecuted() int64 {
+	if x != nil {
+		return x.DateExecuted
+	}
+	return 0
+}
+
+func (x *HistoryOrder) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *HistoryOrder) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetOrderHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*HistoryOrder        `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryResponse) Reset() {
+	*x = GetOrderHistoryResponse{}
+	mi := &file_fyb_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryResponse) ProtoMessage() {}
+
+func (x *GetOrderHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetOrderHistoryResponse) GetOrders() []*HistoryOrder {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type PlaceOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Side          Side                   `protobuf:"varint,1,opt,name=side,proto3,enum=fyb.v1.Side" json:"side,omitempty"`
+	Price         string                 `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	Qty           string                 `protobuf:"bytes,3,opt,name=qty,proto3" json:"qty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceOrderRequest) Reset() {
+	*x = PlaceOrderRequest{}
+	mi := &file_fyb_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceOrderRequest) ProtoMessage() {}
+
+func (x *PlaceOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceOrderRequest.ProtoReflect.Descriptor instead.
+func (*PlaceOrderRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PlaceOrderRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *PlaceOrderRequest) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *PlaceOrderRequest) GetQty() string {
+	if x != nil {
+		return x.Qty
+	}
+	return ""
+}
+
+type PlaceOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        int64                  `protobuf:"varint,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceOrderResponse) Reset() {
+	*x = PlaceOrderResponse{}
+	mi := &file_fyb_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceOrderResponse) ProtoMessage() {}
+
+func (x *PlaceOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceOrderResponse.ProtoReflect.Descriptor instead.
+func (*PlaceOrderResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PlaceOrderResponse) GetTicket() int64 {
+	if x != nil {
+		return x.Ticket
+	}
+	return 0
+}
+
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticket        int64                  `protobuf:"varint,1,opt,name=ticket,proto3" json:"ticket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_fyb_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CancelOrderRequest) GetTicket() int64 {
+	if x != nil {
+		return x.Ticket
+	}
+	return 0
+}
+
+type CancelOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderResponse) Reset() {
+	*x = CancelOrderResponse{}
+	mi := &file_fyb_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderResponse) ProtoMessage() {}
+
+func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{19}
+}
+
+type WithdrawRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount string                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	// "BTC" or "XFERS" (FYB-SG only).
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// Bitcoin address, empty for XFERS.
+	Destination   string `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WithdrawRequest) Reset() {
+	*x = WithdrawRequest{}
+	mi := &file_fyb_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WithdrawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithdrawRequest) ProtoMessage() {}
+
+func (x *WithdrawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithdrawRequest.ProtoReflect.Descriptor instead.
+func (*WithdrawRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WithdrawRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *WithdrawRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WithdrawRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+type WithdrawResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WithdrawResponse) Reset() {
+	*x = WithdrawResponse{}
+	mi := &file_fyb_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WithdrawResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithdrawResponse) ProtoMessage() {}
+
+func (x *WithdrawResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithdrawResponse.ProtoReflect.Descriptor instead.
+func (*WithdrawResponse) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WithdrawResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StreamTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SinceTid      int64                  `protobuf:"varint,1,opt,name=since_tid,json=sinceTid,proto3" json:"since_tid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTradesRequest) Reset() {
+	*x = StreamTradesRequest{}
+	mi := &file_fyb_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTradesRequest) ProtoMessage() {}
+
+func (x *StreamTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTradesRequest.ProtoReflect.Descriptor instead.
+func (*StreamTradesRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StreamTradesRequest) GetSinceTid() int64 {
+	if x != nil {
+		return x.SinceTid
+	}
+	return 0
+}
+
+type StreamOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Depth         int32                  `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamOrderBookRequest) Reset() {
+	*x = StreamOrderBookRequest{}
+	mi := &file_fyb_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamOrderBookRequest) ProtoMessage() {}
+
+func (x *StreamOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fyb_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*StreamOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_fyb_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StreamOrderBookRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+var File_fyb_proto protoreflect.FileDescriptor
+
+const file_fyb_proto_rawDesc = "" +
+	"\n" +
+	"\tfyb.proto\x12\x06fyb.v1\"\x12\n" +
+	"\x10GetTickerRequest\"R\n" +
+	"\x06Ticker\x12\x10\n" +
+	"\x03ask\x18\x01 \x01(\tR\x03ask\x12\x10\n" +
+	"\x03bid\x18\x02 \x01(\tR\x03bid\x12\x12\n" +
+	"\x04last\x18\x03 \x01(\tR\x04last\x12\x10\n" +
+	"\x03vol\x18\x04 \x01(\tR\x03vol\"+\n" +
+	"\x13GetOrderBookRequest\x12\x14\n" +
+	"\x05depth\x18\x01 \x01(\x05R\x05depth\"5\n" +
+	"\x05Level\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\tR\x05price\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\"Q\n" +
+	"\tOrderBook\x12!\n" +
+	"\x04bids\x18\x01 \x03(\v2\r.fyb.v1.LevelR\x04bids\x12!\n" +
+	"\x04asks\x18\x02 \x03(\v2\r.fyb.v1.LevelR\x04asks\"/\n" +
+	"\x10GetTradesRequest\x12\x1b\n" +
+	"\tsince_tid\x18\x01 \x01(\x03R\bsinceTid\"[\n" +
+	"\x05Trade\x12\x10\n" +
+	"\x03tid\x18\x01 \x01(\x03R\x03tid\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\x03R\x04date\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\":\n" +
+	"\x11GetTradesResponse\x12%\n" +
+	"\x06trades\x18\x01 \x03(\v2\r.fyb.v1.TradeR\x06trades\"\x13\n" +
+	"\x11GetAccountRequest\"\x94\x01\n" +
+	"\aAccount\x12\x15\n" +
+	"\x06acc_no\x18\x01 \x01(\x03R\x05accNo\x12\x1f\n" +
+	"\vbtc_balance\x18\x02 \x01(\tR\n" +
+	"btcBalance\x12!\n" +
+	"\ffiat_balance\x18\x03 \x01(\tR\vfiatBalance\x12.\n" +
+	"\x13btc_deposit_address\x18\x04 \x01(\tR\x11btcDepositAddress\"\x19\n" +
+	"\x17GetPendingOrdersRequest\"\x84\x01\n" +
+	"\fPendingOrder\x12\x16\n" +
+	"\x06ticket\x18\x01 \x01(\x03R\x06ticket\x12 \n" +
+	"\x04side\x18\x02 \x01(\x0e2\f.fyb.v1.SideR\x04side\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12\x10\n" +
+	"\x03qty\x18\x04 \x01(\tR\x03qty\x12\x12\n" +
+	"\x04date\x18\x05 \x01(\x03R\x04date\"H\n" +
+	"\x18GetPendingOrdersResponse\x12,\n" +
+	"\x06orders\x18\x01 \x03(\v2\x14.fyb.v1.PendingOrderR\x06orders\".\n" +
+	"\x16GetOrderHistoryRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\"\xd0\x01\n" +
+	"\fHistoryOrder\x12\x16\n" +
+	"\x06ticket\x18\x01 \x01(\x03R\x06ticket\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\tR\x05price\x12\x10\n" +
+	"\x03qty\x18\x03 \x01(\tR\x03qty\x12!\n" +
+	"\fdate_created\x18\x04 \x01(\x03R\vdateCreated\x12#\n" +
+	"\rdate_executed\x18\x05 \x01(\x03R\fdateExecuted\x12 \n" +
+	"\x04side\x18\x06 \x01(\x0e2\f.fyb.v1.SideR\x04side\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\"G\n" +
+	"\x17GetOrderHistoryResponse\x12,\n" +
+	"\x06orders\x18\x01 \x03(\v2\x14.fyb.v1.HistoryOrderR\x06orders\"]\n" +
+	"\x11PlaceOrderRequest\x12 \n" +
+	"\x04side\x18\x01 \x01(\x0e2\f.fyb.v1.SideR\x04side\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\tR\x05price\x12\x10\n" +
+	"\x03qty\x18\x03 \x01(\tR\x03qty\",\n" +
+	"\x12PlaceOrderResponse\x12\x16\n" +
+	"\x06ticket\x18\x01 \x01(\x03R\x06ticket\",\n" +
+	"\x12CancelOrderRequest\x12\x16\n" +
+	"\x06ticket\x18\x01 \x01(\x03R\x06ticket\"\x15\n" +
+	"\x13CancelOrderResponse\"_\n" +
+	"\x0fWithdrawRequest\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\tR\x06amount\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\"\"\n" +
+	"\x10WithdrawResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"2\n" +
+	"\x13StreamTradesRequest\x12\x1b\n" +
+	"\tsince_tid\x18\x01 \x01(\x03R\bsinceTid\".\n" +
+	"\x16StreamOrderBookRequest\x12\x14\n" +
+	"\x05depth\x18\x01 \x01(\x05R\x05depth*9\n" +
+	"\x04Side\x12\x14\n" +
+	"\x10SIDE_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bSIDE_BUY\x10\x01\x12\r\n" +
+	"\tSIDE_SELL\x10\x022\xf5\x05\n" +
+	"\x03FYB\x125\n" +
+	"\tGetTicker\x12\x18.fyb.v1.GetTickerRequest\x1a\x0e.fyb.v1.Ticker\x12>\n" +
+	"\fGetOrderBook\x12\x1b.fyb.v1.GetOrderBookRequest\x1a\x11.fyb.v1.OrderBook\x12@\n" +
+	"\tGetTrades\x12\x18.fyb.v1.GetTradesRequest\x1a\x19.fyb.v1.GetTradesResponse\x128\n" +
+	"\n" +
+	"GetAccount\x12\x19.fyb.v1.GetAccountRequest\x1a\x0f.fyb.v1.Account\x12U\n" +
+	"\x10GetPendingOrders\x12\x1f.fyb.v1.GetPendingOrdersRequest\x1a .fyb.v1.GetPendingOrdersResponse\x12R\n" +
+	"\x0fGetOrderHistory\x12\x1e.fyb.v1.GetOrderHistoryRequest\x1a\x1f.fyb.v1.GetOrderHistoryResponse\x12C\n" +
+	"\n" +
+	"PlaceOrder\x12\x19.fyb.v1.PlaceOrderRequest\x1a\x1a.fyb.v1.PlaceOrderResponse\x12F\n" +
+	"\vCancelOrder\x12\x1a.fyb.v1.CancelOrderRequest\x1a\x1b.fyb.v1.CancelOrderResponse\x12=\n" +
+	"\bWithdraw\x12\x17.fyb.v1.WithdrawRequest\x1a\x18.fyb.v1.WithdrawResponse\x12<\n" +
+	"\fStreamTrades\x12\x1b.fyb.v1.StreamTradesRequest\x1a\r.fyb.v1.Trade0\x01\x12F\n" +
+	"\x0fStreamOrderBook\x12\x1e.fyb.v1.StreamOrderBookRequest\x1a\x11.fyb.v1.OrderBook0\x01B@\n" +
+	"\x16com.github.rakd.fyb.v1P\x01Z$github.com/rakd/go-fyb/fybgrpc/fybpbb\x06proto3"
+
+var (
+	file_fyb_proto_rawDescOnce sync.Once
+	file_fyb_proto_rawDescData []byte
+)
+
+func file_fyb_proto_rawDescGZIP() []byte {
+	file_fyb_proto_rawDescOnce.Do(func() {
+		file_fyb_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fyb_proto_rawDesc), len(file_fyb_proto_rawDesc)))
+	})
+	return file_fyb_proto_rawDescData
+}
+
+var file_fyb_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_fyb_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_fyb_proto_goTypes = []any{
+	(Side)(0),                        // 0: fyb.v1.Side
+	(*GetTickerRequest)(nil),         // 1: fyb.v1.GetTickerRequest
+	(*Ticker)(nil),                   // 2: fyb.v1.Ticker
+	(*GetOrderBookRequest)(nil),      // 3: fyb.v1.GetOrderBookRequest
+	(*Level)(nil),                    // 4: fyb.v1.Level
+	(*OrderBook)(nil),                // 5: fyb.v1.OrderBook
+	(*GetTradesRequest)(nil),         // 6: fyb.v1.GetTradesRequest
+	(*Trade)(nil),                    // 7: fyb.v1.Trade
+	(*GetTradesResponse)(nil),        // 8: fyb.v1.GetTradesResponse
+	(*GetAccountRequest)(nil),        // 9: fyb.v1.GetAccountRequest
+	(*Account)(nil),                  // 10: fyb.v1.Account
+	(*GetPendingOrdersRequest)(nil),  // 11: fyb.v1.GetPendingOrdersRequest
+	(*PendingOrder)(nil),             // 12: fyb.v1.PendingOrder
+	(*GetPendingOrdersResponse)(nil), // 13: fyb.v1.GetPendingOrdersResponse
+	(*GetOrderHistoryRequest)(nil),   // 14: fyb.v1.GetOrderHistoryRequest
+	(*HistoryOrder)(nil),             // 15: fyb.v1.HistoryOrder
+	(*GetOrderHistoryResponse)(nil),  // 16: fyb.v1.GetOrderHistoryResponse
+	(*PlaceOrderRequest)(nil),        // 17: fyb.v1.PlaceOrderRequest
+	(*PlaceOrderResponse)(nil),       // 18: fyb.v1.PlaceOrderResponse
+	(*CancelOrderRequest)(nil),       // 19: fyb.v1.CancelOrderRequest
+	(*CancelOrderResponse)(nil),      // 20: fyb.v1.CancelOrderResponse
+	(*WithdrawRequest)(nil),          // 21: fyb.v1.WithdrawRequest
+	(*WithdrawResponse)(nil),         // 22: fyb.v1.WithdrawResponse
+	(*StreamTradesRequest)(nil),      // 23: fyb.v1.StreamTradesRequest
+	(*StreamOrderBookRequest)(nil),   // 24: fyb.v1.StreamOrderBookRequest
+}
+var file_fyb_proto_depIdxs = []int32{
+	4,  // 0: fyb.v1.OrderBook.bids:type_name -> fyb.v1.Level
+	4,  // 1: fyb.v1.OrderBook.asks:type_name -> fyb.v1.Level
+	7,  // 2: fyb.v1.GetTradesResponse.trades:type_name -> fyb.v1.Trade
+	0,  // 3: fyb.v1.PendingOrder.side:type_name -> fyb.v1.Side
+	12, // 4: fyb.v1.GetPendingOrdersResponse.orders:type_name -> fyb.v1.PendingOrder
+	0,  // 5: fyb.v1.HistoryOrder.side:type_name -> fyb.v1.Side
+	15, // 6: fyb.v1.GetOrderHistoryResponse.orders:type_name -> fyb.v1.HistoryOrder
+	0,  // 7: fyb.v1.PlaceOrderRequest.side:type_name -> fyb.v1.Side
+	1,  // 8: fyb.v1.FYB.GetTicker:input_type -> fyb.v1.GetTickerRequest
+	3,  // 9: fyb.v1.FYB.GetOrderBook:input_type -> fyb.v1.GetOrderBookRequest
+	6,  // 10: fyb.v1.FYB.GetTrades:input_type -> fyb.v1.GetTradesRequest
+	9,  // 11: fyb.v1.FYB.GetAccount:input_type -> fyb.v1.GetAccountRequest
+	11, // 12: fyb.v1.FYB.GetPendingOrders:input_type -> fyb.v1.GetPendingOrdersRequest
+	14, // 13: fyb.v1.FYB.GetOrderHistory:input_type -> fyb.v1.GetOrderHistoryRequest
+	17, // 14: fyb.v1.FYB.PlaceOrder:input_type -> fyb.v1.PlaceOrderRequest
+	19, // 15: fyb.v1.FYB.CancelOrder:input_type -> fyb.v1.CancelOrderRequest
+	21, // 16: fyb.v1.FYB.Withdraw:input_type -> fyb.v1.WithdrawRequest
+	23, // 17: fyb.v1.FYB.StreamTrades:input_type -> fyb.v1.StreamTradesRequest
+	24, // 18: fyb.v1.FYB.StreamOrderBook:input_type -> fyb.v1.StreamOrderBookRequest
+	2,  // 19: fyb.v1.FYB.GetTicker:output_type -> fyb.v1.Ticker
+	5,  // 20: fyb.v1.FYB.GetOrderBook:output_type -> fyb.v1.OrderBook
+	8,  // 21: fyb.v1.FYB.GetTrades:output_type -> fyb.v1.GetTradesResponse
+	10, // 22: fyb.v1.FYB.GetAccount:output_type -> fyb.v1.Account
+	13, // 23: fyb.v1.FYB.GetPendingOrders:output_type -> fyb.v1.GetPendingOrdersResponse
+	16, // 24: fyb.v1.FYB.GetOrderHistory:output_type -> fyb.v1.GetOrderHistoryResponse
+	18, // 25: fyb.v1.FYB.PlaceOrder:output_type -> fyb.v1.PlaceOrderResponse
+	20, // 26: fyb.v1.FYB.CancelOrder:output_type -> fyb.v1.CancelOrderResponse
+	22, // 27: fyb.v1.FYB.Withdraw:output_type -> fyb.v1.WithdrawResponse
+	7,  // 28: fyb.v1.FYB.StreamTrades:output_type -> fyb.v1.Trade
+	5,  // 29: fyb.v1.FYB.StreamOrderBook:output_type -> fyb.v1.OrderBook
+	19, // [19:30] is the sub-list for method output_type
+	8,  // [8:19] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_fyb_proto_init() }
+func file_fyb_proto_init() {
+	if File_fyb_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fyb_proto_rawDesc), len(file_fyb_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fyb_proto_goTypes,
+		DependencyIndexes: file_fyb_proto_depIdxs,
+		EnumInfos:         file_fyb_proto_enumTypes,
+		MessageInfos:      file_fyb_proto_msgTypes,
+	}.Build()
+	File_fyb_proto = out.File
+	file_fyb_proto_goTypes = nil
+	file_fyb_proto_depIdxs = nil
+}
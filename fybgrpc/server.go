@@ -0,0 +1,352 @@
+// Package fybgrpc serves the FYB API over gRPC, as defined in
+// fybpb/fyb.proto, by wrapping a Fyb client. Streaming RPCs are fed by a
+// shared fyb.Tracker so that any number of streams cost one poller.
+package fybgrpc
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/fybgrpc/fybpb"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements fybpb.FYBServer.
+type Server struct {
+	fybpb.UnimplementedFYBServer
+
+	api           *fyb.Fyb
+	tracker       *fyb.Tracker
+	allowTrade    bool
+	allowWithdraw bool
+}
+
+// NewServer returns a Server for api. tracker feeds StreamTrades and
+// StreamOrderBook and must be started by the caller. Trading and
+// withdrawals are refused until enabled.
+func NewServer(api *fyb.Fyb, tracker *fyb.Tracker) *Server {
+	return &Server{api: api, tracker: tracker}
+}
+
+// AllowTrading enables PlaceOrder and CancelOrder.
+func (s *Server) AllowTrading(allow bool) {
+	s.allowTrade = allow
+}
+
+// AllowWithdrawals enables Withdraw.
+func (s *Server) AllowWithdrawals(allow bool) {
+	s.allowWithdraw = allow
+}
+
+func upstream(err error) error {
+	return status.Errorf(codes.Unavailable, "fyb: %v", err)
+}
+
+// apiError checks the error field of a private API response.
+func apiError(code int64, msg string) error {
+	if code == 0 {
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition, "fyb: %s", msg)
+}
+
+func toSide(t string) fybpb.Side {
+	switch t {
+	case "B":
+		return fybpb.Side_SIDE_BUY
+	case "S":
+		return fybpb.Side_SIDE_SELL
+	}
+	return fybpb.Side_SIDE_UNSPECIFIED
+}
+
+func toBook(book fyb.OrderBook, depth int32) *fybpb.OrderBook {
+	res := &fybpb.OrderBook{}
+	for i, l := range book.Bids {
+		if depth > 0 && int32(i) >= depth {
+			break
+		}
+		res.Bids = append(res.Bids, &fybpb.Level{Price: l.Price.String(), Amount: l.Amount.String()})
+	}
+	for i, l := range book.Asks {
+		if depth > 0 && int32(i) >= depth {
+			break
+		}
+		res.Asks = append(res.Asks, &fybpb.Level{Price: l.Price.String(), Amount: l.Amount.String()})
+	}
+	return res
+}
+
+func toTrade(t fyb.Trade) *fybpb.Trade {
+	return &fybpb.Trade{Tid: t.TID, Date: t.Date, Price: t.Price.String(), Amount: t.Amount.String()}
+}
+
+// GetTicker implements fybpb.FYBServer.
+func (s *Server) GetTicker(ctx context.Context, req *fybpb.GetTickerRequest) (*fybpb.Ticker, error) {
+	ticker, err := s.api.GetTicker()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	return &fybpb.Ticker{
+		Ask:  ticker.Ask.String(),
+		Bid:  ticker.Bid.String(),
+		Last: ticker.Last.String(),
+		Vol:  ticker.Vol.String(),
+	}, nil
+}
+
+// GetOrderBook implements fybpb.FYBServer.
+func (s *Server) GetOrderBook(ctx context.Context, req *fybpb.GetOrderBookRequest) (*fybpb.OrderBook, error) {
+	book, err := s.api.GetOrderBook()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	return toBook(book, req.GetDepth()), nil
+}
+
+// GetTrades implements fybpb.FYBServer.
+func (s *Server) GetTrades(ctx context.Context, req *fybpb.GetTradesRequest) (*fybpb.GetTradesResponse, error) {
+	trades, err := s.api.GetTradeHistory(req.GetSinceTid())
+	if err != nil {
+		return nil, upstream(err)
+	}
+	res := &fybpb.GetTradesResponse{}
+	for _, t := range trades {
+		res.Trades = append(res.Trades, toTrade(t))
+	}
+	return res, nil
+}
+
+// GetAccount implements fybpb.FYBServer.
+func (s *Server) GetAccount(ctx context.Context, req *fybpb.GetAccountRequest) (*fybpb.Account, error) {
+	info, err := s.api.GetAccountInfo()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(info.Error, info.Msg); err != nil {
+		return nil, err
+	}
+	return &fybpb.Account{
+		AccNo:             info.AccNo,
+		BtcBalance:        info.BtcBal.String(),
+		FiatBalance:       info.FiatBal(s.api.Currency()).String(),
+		BtcDepositAddress: info.BtcDeposit,
+	}, nil
+}
+
+// GetPendingOrders implements fybpb.FYBServer.
+func (s *Server) GetPendingOrders(ctx context.Context, req *fybpb.GetPendingOrdersRequest) (*fybpb.GetPendingOrdersResponse, error) {
+	pending, err := s.api.GetPendingOrders()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(pending.Error, pending.Msg); err != nil {
+		return nil, err
+	}
+	res := &fybpb.GetPendingOrdersResponse{}
+	for _, o := range pending.Orders {
+		price, err := fyb.ParseAmount(o.Price)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		qty, err := fyb.ParseAmount(o.Qty)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		res.Orders = append(res.Orders, &fybpb.PendingOrder{
+			Ticket: o.Ticket,
+			Side:   toSide(o.Type),
+			Price:  price.String(),
+			Qty:    qty.String(),
+			Date:   o.Date,
+		})
+	}
+	return res, nil
+}
+
+// GetOrderHistory implements fybpb.FYBServer.
+func (s *Server) GetOrderHistory(ctx context.Context, req *fybpb.GetOrderHistoryRequest) (*fybpb.GetOrderHistoryResponse, error) {
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = 20
+	}
+	history, err := s.api.GetOrderHistory(limit)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(history.Error, history.Msg); err != nil {
+		return nil, err
+	}
+	res := &fybpb.GetOrderHistoryResponse{}
+	for _, o := range history.Orders {
+		price, err := fyb.ParseAmount(o.Price)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		qty, err := fyb.ParseAmount(o.Qty)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		res.Orders = append(res.Orders, &fybpb.HistoryOrder{
+			Ticket:       o.Ticket,
+			Price:        price.String(),
+			Qty:          qty.String(),
+			DateCreated:  o.DateCreated,
+			DateExecuted: o.DateExecuted,
+			Side:         toSide(o.Type),
+			Status:       o.Status,
+		})
+	}
+	return res, nil
+}
+
+func parsePositive(name, s string) (float64, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil || !d.IsPositive() {
+		return 0, status.Errorf(codes.InvalidArgument, "%s must be a positive number, got %q", name, s)
+	}
+	f, _ := d.Float64()
+	return f, nil
+}
+
+// PlaceOrder implements fybpb.FYBServer.
+func (s *Server) PlaceOrder(ctx context.Context, req *fybpb.PlaceOrderRequest) (*fybpb.PlaceOrderResponse, error) {
+	if !s.allowTrade {
+		return nil, status.Error(codes.PermissionDenied, "trading is disabled on this server")
+	}
+	var orderType string
+	switch req.GetSide() {
+	case fybpb.Side_SIDE_BUY:
+		orderType = "B"
+	case fybpb.Side_SIDE_SELL:
+		orderType = "S"
+	default:
+		return nil, status.Error(codes.InvalidArgument, "side is required")
+	}
+	price, err := parsePositive("price", req.GetPrice())
+	if err != nil {
+		return nil, err
+	}
+	qty, err := parsePositive("qty", req.GetQty())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.api.PlaceOrder(orderType, price, qty)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	ticket, err := strconv.ParseInt(res.PendingOID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, "fyb: unexpected pending_oid %q", res.PendingOID)
+	}
+	return &fybpb.PlaceOrderResponse{Ticket: ticket}, nil
+}
+
+// CancelOrder implements fybpb.FYBServer.
+func (s *Server) CancelOrder(ctx context.Context, req *fybpb.CancelOrderRequest) (*fybpb.CancelOrderResponse, error) {
+	if !s.allowTrade {
+		return nil, status.Error(codes.PermissionDenied, "trading is disabled on this server")
+	}
+	res, err := s.api.CancelPendingOrder(req.GetTicket())
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	return &fybpb.CancelOrderResponse{}, nil
+}
+
+// Withdraw implements fybpb.FYBServer.
+func (s *Server) Withdraw(ctx context.Context, req *fybpb.WithdrawRequest) (*fybpb.WithdrawResponse, error) {
+	if !s.allowWithdraw {
+		return nil, status.Error(codes.PermissionDenied, "withdrawals are disabled on this server")
+	}
+	amount, err := parsePositive("amount", req.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.api.Withdraw(amount, req.GetDestination(), req.GetType())
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	return &fybpb.WithdrawResponse{Id: res.Msg}, nil
+}
+
+// StreamTrades implements fybpb.FYBServer.
+func (s *Server) StreamTrades(req *fybpb.StreamTradesRequest, stream fybpb.FYB_StreamTradesServer) error {
+	// Subscribe before the backfill so no trade falls in between.
+	ch, unsubscribe := s.tracker.SubscribeTrades()
+	defer unsubscribe()
+
+	last := req.GetSinceTid()
+	if last > 0 {
+		trades, err := s.api.GetTradeHistory(last)
+		if err != nil {
+			return upstream(err)
+		}
+		sort.Slice(trades, func(i, j int) bool { return trades[i].TID < trades[j].TID })
+		for _, t := range trades {
+			if t.TID <= last {
+				continue
+			}
+			if err := stream.Send(toTrade(t)); err != nil {
+				return err
+			}
+			last = t.TID
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case batch := <-ch:
+			if len(batch) > 0 && last > 0 && batch[0].TID > last+1 {
+				// The tracker drops batches for slow readers; fetch
+				// whatever may have been missed.
+				missed, err := s.api.GetTradeHistory(last)
+				if err != nil {
+					return upstream(err)
+				}
+				batch = append(missed, batch...)
+				sort.Slice(batch, func(i, j int) bool { return batch[i].TID < batch[j].TID })
+			}
+			for _, t := range batch {
+				if t.TID <= last {
+					continue
+				}
+				if err := stream.Send(toTrade(t)); err != nil {
+					return err
+				}
+				last = t.TID
+			}
+		}
+	}
+}
+
+// StreamOrderBook implements fybpb.FYBServer.
+func (s *Server) StreamOrderBook(req *fybpb.StreamOrderBookRequest, stream fybpb.FYB_StreamOrderBookServer) error {
+	ch, unsubscribe := s.tracker.SubscribeBook()
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case book := <-ch:
+			if err := stream.Send(toBook(book, req.GetDepth())); err != nil {
+				return err
+			}
+		}
+	}
+}
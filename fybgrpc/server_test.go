@@ -0,0 +1,163 @@
+package fybgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/fybgrpc/fybpb"
+	"github.com/rakd/go-fyb/gateway"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, opts ...grpc.ServerOption) (fybpb.FYBClient, *fyb.Tracker, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tickerdetailed.json":
+			w.Write([]byte(`{"ask":"1012.48","bid":"1005.17","last":"1011.00","vol":"1.2"}`))
+		case "/orderbook.json":
+			w.Write([]byte(`{"asks":[[1012.48,0.5],[1020,1.25]],"bids":[[1005.17,0.3],[1000,2.5]]}`))
+		case "/trades.json":
+			w.Write([]byte(`[{"amount":"0.0100","date":1387975210,"price":"1011.00","tid":2218611}]`))
+		case "/getpendingorders":
+			w.Write([]byte(`{"error":0,"orders":[{"date":1387099682,"price":"S$5.00","qty":"0.99000000BTC","ticket":6,"type":"S"}]}`))
+		case "/getorderhistory":
+			w.Write([]byte(`{"error":0,"orders":[{"date_created":1,"date_executed":2,"price":"S$3.00","qty":"2.00000000BTC","status":"F","ticket":6,"type":"S"}]}`))
+		}
+	}))
+	api := fyb.New(ts.URL, "key", "secret")
+	tracker := fyb.NewTracker(api, 0, 0)
+	client, done := serve(t, api, tracker, opts...)
+	return client, tracker, func() {
+		done()
+		ts.Close()
+	}
+}
+
+func serve(t *testing.T, api *fyb.Fyb, tracker *fyb.Tracker, opts ...grpc.ServerOption) (fybpb.FYBClient, func()) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer(opts...)
+	fybpb.RegisterFYBServer(gs, NewServer(api, tracker))
+	go gs.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	return fybpb.NewFYBClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+	}
+}
+
+func TestUnary(t *testing.T) {
+	client, _, done := newTestClient(t)
+	defer done()
+	ctx := context.Background()
+
+	ticker, err := client.GetTicker(ctx, &fybpb.GetTickerRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "1012.48", ticker.Ask)
+
+	book, err := client.GetOrderBook(ctx, &fybpb.GetOrderBookRequest{Depth: 1})
+	require.NoError(t, err)
+	require.Len(t, book.Bids, 1)
+	require.Equal(t, "1005.17", book.Bids[0].Price)
+
+	_, err = client.PlaceOrder(ctx, &fybpb.PlaceOrderRequest{Side: fybpb.Side_SIDE_BUY, Price: "1", Qty: "1"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	history, err := client.GetOrderHistory(ctx, &fybpb.GetOrderHistoryRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, fybpb.Side_SIDE_SELL, history.Orders[0].Side)
+	require.Equal(t, "F", history.Orders[0].Status)
+}
+
+func TestStreamTradesBackfillsGaps(t *testing.T) {
+	var mu sync.Mutex
+	newest := int64(101)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+		var trades fyb.Trades
+		for tid := since + 1; tid <= newest; tid++ {
+			trades = append(trades, fyb.Trade{TID: tid, Date: 1})
+		}
+		json.NewEncoder(w).Encode(trades)
+	}))
+	defer ts.Close()
+	api := fyb.New(ts.URL, "", "")
+	// The tracker starts after trade 102, as if it had dropped the batch
+	// with it.
+	tracker := fyb.NewTracker(api, 0, 102)
+	client, done := serve(t, api, tracker)
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamTrades(ctx, &fybpb.StreamTradesRequest{SinceTid: 100})
+	require.NoError(t, err)
+	trade, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, int64(101), trade.Tid)
+
+	mu.Lock()
+	newest = 103
+	mu.Unlock()
+	tracker.Poll()
+	for _, tid := range []int64{102, 103} {
+		trade, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, tid, trade.Tid)
+	}
+}
+
+func TestStreamOrderBook(t *testing.T) {
+	client, tracker, done := newTestClient(t)
+	defer done()
+	tracker.Poll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamOrderBook(ctx, &fybpb.StreamOrderBookRequest{Depth: 2})
+	require.NoError(t, err)
+	book, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, book.Asks, 2)
+	require.Equal(t, "1012.48", book.Asks[0].Price)
+}
+
+func TestAuth(t *testing.T) {
+	auth, err := Auth([]gateway.Caller{{Name: "reader", Token: "r-token", Scopes: []gateway.Scope{gateway.ScopeRead}}})
+	require.NoError(t, err)
+	client, _, done := newTestClient(t, auth...)
+	defer done()
+
+	_, err = client.GetTicker(context.Background(), &fybpb.GetTickerRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer r-token")
+	pending, err := client.GetPendingOrders(ctx, &fybpb.GetPendingOrdersRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "5", pending.Orders[0].Price)
+	require.Equal(t, "0.99", pending.Orders[0].Qty)
+
+	_, err = client.PlaceOrder(ctx, &fybpb.PlaceOrderRequest{Side: fybpb.Side_SIDE_BUY, Price: "1", Qty: "1"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = Auth([]gateway.Caller{{Name: "open"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,489 @@
+// Package gateway exposes a Fyb client as a normalized REST/JSON API so
+// that several internal services can share one set of FYB credentials and
+// one rate limiter.
+//
+// Callers authenticate with "Authorization: Bearer <token>" and are granted
+// scopes:
+//
+//	read      GET  /v1/ticker, /v1/orderbook?depth=N, /v1/trades?since=TID,
+//	          GET  /v1/account, /v1/orders, /v1/orders/history?limit=N
+//	trade     POST /v1/orders {"side":"buy","price":"1000","qty":"0.1"}
+//	          DELETE /v1/orders/{ticket}
+//	withdraw  POST /v1/withdrawals {"amount":"0.1","type":"BTC","destination":"1Ab..."}
+//
+// Errors are returned as {"error": "..."} with an HTTP status code.
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Scope is a permission granted to a caller.
+type Scope string
+
+// Scopes understood by the gateway.
+const (
+	ScopeRead     Scope = "read"
+	ScopeTrade    Scope = "trade"
+	ScopeWithdraw Scope = "withdraw"
+)
+
+// Caller is a client of the gateway identified by a bearer token.
+type Caller struct {
+	Name   string  `json:"name"`
+	Token  string  `json:"token"`
+	Scopes []Scope `json:"scopes"`
+}
+
+// Has reports whether the caller was granted scope.
+func (c *Caller) Has(scope Scope) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Gateway is an http.Handler serving the normalized API. All callers share
+// the rate limiter of the wrapped client.
+type Gateway struct {
+	api     *fyb.Fyb
+	callers []Caller
+	logger  fyb.Logger
+}
+
+// maxRequestBody is the largest request body the gateway reads.
+const maxRequestBody = 64 << 10
+
+// New returns a Gateway in front of api for the given callers. Every
+// caller needs a non-empty, unique token.
+func New(api *fyb.Fyb, callers []Caller) (*Gateway, error) {
+	if err := ValidateCallers(callers); err != nil {
+		return nil, err
+	}
+	return &Gateway{api: api, callers: callers}, nil
+}
+
+// ValidateCallers checks that every caller has a non-empty, unique token
+// and only known scopes.
+func ValidateCallers(callers []Caller) error {
+	tokens := map[string]bool{}
+	for _, c := range callers {
+		if c.Token == "" {
+			return fmt.Errorf("caller %q has no token", c.Name)
+		}
+		if tokens[c.Token] {
+			return fmt.Errorf("caller %q reuses another caller's token", c.Name)
+		}
+		tokens[c.Token] = true
+		for _, s := range c.Scopes {
+			if s != ScopeRead && s != ScopeTrade && s != ScopeWithdraw {
+				return fmt.Errorf("caller %q has unknown scope %q", c.Name, s)
+			}
+		}
+	}
+	return nil
+}
+
+// SetLogger sets the logger used for the audit log of trades and
+// withdrawals. *slog.Logger can be passed directly.
+func (g *Gateway) SetLogger(logger fyb.Logger) {
+	g.logger = logger
+}
+
+func (g *Gateway) audit(msg string, args ...interface{}) {
+	if g.logger != nil {
+		g.logger.Info(msg, args...)
+	}
+}
+
+// Level is one price level of the order book.
+type Level struct {
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Book is the normalized order book, best prices first.
+type Book struct {
+	Bids []Level `json:"bids"`
+	Asks []Level `json:"asks"`
+}
+
+// Trade is a normalized public trade.
+type Trade struct {
+	ID     int64           `json:"id"`
+	Time   time.Time       `json:"time"`
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Account is the normalized account balance.
+type Account struct {
+	Number     int64           `json:"number"`
+	BTC        decimal.Decimal `json:"btc"`
+	Fiat       decimal.Decimal `json:"fiat"`
+	BTCDeposit string          `json:"btc_deposit"`
+}
+
+// Order is a normalized pending or historical order.
+type Order struct {
+	Ticket     int64           `json:"ticket"`
+	Side       string          `json:"side,omitempty"` // "buy" or "sell"
+	Price      decimal.Decimal `json:"price"`
+	Qty        decimal.Decimal `json:"qty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ExecutedAt *time.Time      `json:"executed_at,omitempty"`
+}
+
+// PlaceOrderRequest is the body of POST /v1/orders.
+type PlaceOrderRequest struct {
+	Side  string          `json:"side"`
+	Price decimal.Decimal `json:"price"`
+	Qty   decimal.Decimal `json:"qty"`
+}
+
+// WithdrawRequest is the body of POST /v1/withdrawals.
+type WithdrawRequest struct {
+	Amount      decimal.Decimal `json:"amount"`
+	Type        string          `json:"type"`
+	Destination string          `json:"destination"`
+}
+
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &httpError{status, fmt.Sprintf(format, args...)}
+}
+
+// upstream wraps an error from FYB.
+func upstream(err error) error {
+	return errorf(http.StatusBadGateway, "fyb: %v", err)
+}
+
+// apiError checks the error field of a private API response.
+func apiError(code int64, msg string) error {
+	if code == 0 {
+		return nil
+	}
+	return errorf(http.StatusBadGateway, "fyb: %s", msg)
+}
+
+func side(t string) string {
+	switch t {
+	case "B":
+		return "buy"
+	case "S":
+		return "sell"
+	}
+	return ""
+}
+
+// authenticate finds the caller for the request's bearer token.
+func (g *Gateway) authenticate(r *http.Request) *Caller {
+	return Lookup(g.callers, r.Header.Get("Authorization"))
+}
+
+// Lookup returns the caller for an "Authorization: Bearer <token>" value,
+// or nil. Tokens are compared in constant time and empty tokens never
+// match.
+func Lookup(callers []Caller, authorization string) *Caller {
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil
+	}
+	token := []byte(strings.TrimPrefix(authorization, "Bearer "))
+	if len(token) == 0 {
+		return nil
+	}
+	var found *Caller
+	for i := range callers {
+		if callers[i].Token != "" && subtle.ConstantTimeCompare(token, []byte(callers[i].Token)) == 1 {
+			found = &callers[i]
+		}
+	}
+	return found
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	caller := g.authenticate(r)
+	if caller == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var scope Scope
+	var handler func(*http.Request, *Caller) (interface{}, error)
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == "GET" && path == "/v1/ticker":
+		scope, handler = ScopeRead, g.ticker
+	case r.Method == "GET" && path == "/v1/orderbook":
+		scope, handler = ScopeRead, g.orderbook
+	case r.Method == "GET" && path == "/v1/trades":
+		scope, handler = ScopeRead, g.trades
+	case r.Method == "GET" && path == "/v1/account":
+		scope, handler = ScopeRead, g.account
+	case r.Method == "GET" && path == "/v1/orders":
+		scope, handler = ScopeRead, g.pendingOrders
+	case r.Method == "GET" && path == "/v1/orders/history":
+		scope, handler = ScopeRead, g.orderHistory
+	case r.Method == "POST" && path == "/v1/orders":
+		scope, handler = ScopeTrade, g.placeOrder
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/v1/orders/"):
+		scope, handler = ScopeTrade, g.cancelOrder
+	case r.Method == "POST" && path == "/v1/withdrawals":
+		scope, handler = ScopeWithdraw, g.withdraw
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if !caller.Has(scope) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": fmt.Sprintf("scope %q required", scope)})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	res, err := handler(r, caller)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if he, ok := err.(*httpError); ok {
+			status = he.status
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func queryInt(r *http.Request, name string, def int64) (int64, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "bad %s: %q", name, s)
+	}
+	return v, nil
+}
+
+func (g *Gateway) ticker(r *http.Request, c *Caller) (interface{}, error) {
+	ticker, err := g.api.GetTicker()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	return ticker, nil
+}
+
+func (g *Gateway) orderbook(r *http.Request, c *Caller) (interface{}, error) {
+	depth, err := queryInt(r, "depth", 0)
+	if err != nil {
+		return nil, err
+	}
+	book, err := g.api.GetOrderBook()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	res := Book{Bids: []Level{}, Asks: []Level{}}
+	for i, l := range book.Bids {
+		if depth > 0 && int64(i) >= depth {
+			break
+		}
+		res.Bids = append(res.Bids, Level{l.Price, l.Amount})
+	}
+	for i, l := range book.Asks {
+		if depth > 0 && int64(i) >= depth {
+			break
+		}
+		res.Asks = append(res.Asks, Level{l.Price, l.Amount})
+	}
+	return res, nil
+}
+
+func (g *Gateway) trades(r *http.Request, c *Caller) (interface{}, error) {
+	since, err := queryInt(r, "since", 0)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := g.api.GetTradeHistory(since)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	res := []Trade{}
+	for _, t := range trades {
+		res = append(res, Trade{t.TID, time.Unix(t.Date, 0).UTC(), t.Price, t.Amount})
+	}
+	return res, nil
+}
+
+func (g *Gateway) account(r *http.Request, c *Caller) (interface{}, error) {
+	info, err := g.api.GetAccountInfo()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(info.Error, info.Msg); err != nil {
+		return nil, err
+	}
+	return Account{info.AccNo, info.BtcBal, info.FiatBal(g.api.Currency()), info.BtcDeposit}, nil
+}
+
+func (g *Gateway) pendingOrders(r *http.Request, c *Caller) (interface{}, error) {
+	pending, err := g.api.GetPendingOrders()
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(pending.Error, pending.Msg); err != nil {
+		return nil, err
+	}
+	res := []Order{}
+	for _, o := range pending.Orders {
+		price, err := fyb.ParseAmount(o.Price)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		qty, err := fyb.ParseAmount(o.Qty)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		res = append(res, Order{
+			Ticket:    o.Ticket,
+			Side:      side(o.Type),
+			Price:     price,
+			Qty:       qty,
+			CreatedAt: time.Unix(o.Date, 0).UTC(),
+		})
+	}
+	return res, nil
+}
+
+func (g *Gateway) orderHistory(r *http.Request, c *Caller) (interface{}, error) {
+	limit, err := queryInt(r, "limit", 20)
+	if err != nil {
+		return nil, err
+	}
+	history, err := g.api.GetOrderHistory(limit)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(history.Error, history.Msg); err != nil {
+		return nil, err
+	}
+	res := []Order{}
+	for _, o := range history.Orders {
+		price, err := fyb.ParseAmount(o.Price)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		qty, err := fyb.ParseAmount(o.Qty)
+		if err != nil {
+			return nil, upstream(err)
+		}
+		order := Order{
+			Ticket:    o.Ticket,
+			Side:      side(o.Type),
+			Price:     price,
+			Qty:       qty,
+			CreatedAt: time.Unix(o.DateCreated, 0).UTC(),
+		}
+		if o.DateExecuted != 0 {
+			executed := time.Unix(o.DateExecuted, 0).UTC()
+			order.ExecutedAt = &executed
+		}
+		res = append(res, order)
+	}
+	return res, nil
+}
+
+func (g *Gateway) placeOrder(r *http.Request, c *Caller) (interface{}, error) {
+	var req PlaceOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorf(http.StatusBadRequest, "bad request: %v", err)
+	}
+	if !req.Price.IsPositive() || !req.Qty.IsPositive() {
+		return nil, errorf(http.StatusBadRequest, "price and qty must be positive")
+	}
+	var orderType string
+	switch strings.ToLower(req.Side) {
+	case "buy":
+		orderType = "B"
+	case "sell":
+		orderType = "S"
+	default:
+		return nil, errorf(http.StatusBadRequest, "side must be buy or sell")
+	}
+	price, _ := req.Price.Float64()
+	qty, _ := req.Qty.Float64()
+	g.audit("place order", "caller", c.Name, "side", req.Side, "price", req.Price.String(), "qty", req.Qty.String())
+	res, err := g.api.PlaceOrder(orderType, price, qty)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	ticket, err := strconv.ParseInt(res.PendingOID, 10, 64)
+	if err != nil {
+		g.audit("order placed without ticket", "caller", c.Name, "pending_oid", res.PendingOID)
+		return nil, errorf(http.StatusBadGateway, "fyb: unexpected pending_oid %q", res.PendingOID)
+	}
+	g.audit("order placed", "caller", c.Name, "ticket", ticket)
+	return map[string]int64{"ticket": ticket}, nil
+}
+
+func (g *Gateway) cancelOrder(r *http.Request, c *Caller) (interface{}, error) {
+	s := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/"), "/v1/orders/")
+	ticket, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "bad ticket: %q", s)
+	}
+	g.audit("cancel order", "caller", c.Name, "ticket", ticket)
+	res, err := g.api.CancelPendingOrder(ticket)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	return map[string]int64{"ticket": ticket}, nil
+}
+
+func (g *Gateway) withdraw(r *http.Request, c *Caller) (interface{}, error) {
+	var req WithdrawRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorf(http.StatusBadRequest, "bad request: %v", err)
+	}
+	if !req.Amount.IsPositive() {
+		return nil, errorf(http.StatusBadRequest, "amount must be positive")
+	}
+	amount, _ := req.Amount.Float64()
+	g.audit("withdraw", "caller", c.Name, "amount", req.Amount.String(), "type", req.Type, "destination", req.Destination)
+	res, err := g.api.Withdraw(amount, req.Destination, req.Type)
+	if err != nil {
+		return nil, upstream(err)
+	}
+	if err := apiError(res.Error, res.Msg); err != nil {
+		return nil, err
+	}
+	return map[string]string{"id": res.Msg}, nil
+}
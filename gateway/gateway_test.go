@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGateway(t *testing.T, hits *[]string) (*Gateway, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits = append(*hits, r.URL.Path)
+		switch r.URL.Path {
+		case "/orderbook.json":
+			w.Write([]byte(`{"asks":[[1012.48,0.5],[1020,1.25]],"bids":[[1005.17,0.3],[1000,2.5]]}`))
+		case "/getorderhistory":
+			w.Write([]byte(`{"error":0,"orders":[{"date_created":1387971414,"date_executed":1387971414,"price":"S$3.00","qty":"2.00000000BTC","status":"A","ticket":11,"type":"B"}]}`))
+		case "/placeorder":
+			r.ParseForm()
+			require.Equal(t, "B", r.PostForm.Get("type"))
+			w.Write([]byte(`{"error":0,"msg":"","pending_oid":"28"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	gw, err := New(fyb.New(ts.URL, "key", "secret"), []Caller{
+		{Name: "reader", Token: "r-token", Scopes: []Scope{ScopeRead}},
+		{Name: "trader", Token: "t-token", Scopes: []Scope{ScopeRead, ScopeTrade}},
+	})
+	require.NoError(t, err)
+	return gw, ts.Close
+}
+
+func do(gw *Gateway, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthAndScopes(t *testing.T) {
+	var hits []string
+	gw, done := newTestGateway(t, &hits)
+	defer done()
+
+	require.Equal(t, http.StatusUnauthorized, do(gw, "GET", "/v1/ticker", "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, do(gw, "GET", "/v1/ticker", "wrong", "").Code)
+	rec := do(gw, "POST", "/v1/orders", "r-token", `{"side":"buy","price":"1000","qty":"0.1"}`)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Empty(t, hits)
+
+	rec = do(gw, "POST", "/v1/orders", "t-token", `{"side":"buy","price":"1000","qty":"0.1"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"ticket":28}`, rec.Body.String())
+
+	big := `{"side":"buy","price":"1000","qty":"0.1","pad":"` + strings.Repeat("x", maxRequestBody) + `"}`
+	require.Equal(t, http.StatusBadRequest, do(gw, "POST", "/v1/orders", "t-token", big).Code)
+
+	_, err := New(fyb.New("http://127.0.0.1:1", "", ""), []Caller{{Name: "open", Scopes: []Scope{ScopeRead}}})
+	require.EqualError(t, err, `caller "open" has no token`)
+}
+
+func TestNormalizedResponses(t *testing.T) {
+	var hits []string
+	gw, done := newTestGateway(t, &hits)
+	defer done()
+
+	rec := do(gw, "GET", "/v1/orderbook?depth=1", "r-token", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var book Book
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &book))
+	require.Len(t, book.Bids, 1)
+	require.Len(t, book.Asks, 1)
+	require.Equal(t, "1005.17", book.Bids[0].Price.String())
+
+	rec = do(gw, "GET", "/v1/orders/history?limit=5", "r-token", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var orders []Order
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &orders))
+	require.Len(t, orders, 1)
+	require.Equal(t, "3", orders[0].Price.String())
+	require.Equal(t, "2", orders[0].Qty.String())
+	require.NotNil(t, orders[0].ExecutedAt)
+
+	rec = do(gw, "GET", "/v1/trades?since=abc", "r-token", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
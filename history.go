@@ -0,0 +1,223 @@
+package fyb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HistoryFilter selects orders when walking the order history. Zero fields
+// match all orders.
+type HistoryFilter struct {
+	From   time.Time // orders created at or after From
+	To     time.Time // orders created before To
+	Side   string    // "B" or "S"
+	Status string    // FYB status code, e.g. "A" or "F"
+}
+
+func (f HistoryFilter) match(o HistoryOrder) bool {
+	switch {
+	case !f.From.IsZero() && o.DateCreated < f.From.Unix():
+		return false
+	case !f.To.IsZero() && o.DateCreated >= f.To.Unix():
+		return false
+	case f.Side != "" && o.Type != f.Side:
+		return false
+	case f.Status != "" && o.Status != f.Status:
+		return false
+	}
+	return true
+}
+
+// ErrHistoryTruncated is returned by HistoryIterator.Err when the walk
+// stopped at the maximum page size before reaching the oldest order.
+var ErrHistoryTruncated = errors.New("order history is larger than the maximum page size")
+
+// OrderHistory walks the complete order history. FYB only returns the
+// newest orders up to a limit, so every page asks for twice as many orders
+// as the previous one, up to a maximum, and skips those already returned.
+// A walk stops with ErrHistoryTruncated once a page at the maximum size,
+// or one that brought no new orders, is still full.
+//
+// Orders are remembered by ticket. Once a walk has reached the oldest order,
+// later walks stop paging at the first cached ticket and take older orders
+// from the cache, so their status is as of when they were fetched. Call
+// Reset to forget them.
+type OrderHistory struct {
+	api         *Fyb
+	pageSize    int64
+	maxPageSize int64
+
+	mu       sync.Mutex
+	orders   map[int64]HistoryOrder
+	complete bool // orders holds everything older than its newest order
+}
+
+// NewOrderHistory returns an OrderHistory fetching 100 orders in the first
+// page and at most 10000 in one page.
+func NewOrderHistory(api *Fyb) *OrderHistory {
+	return &OrderHistory{
+		api:         api,
+		pageSize:    100,
+		maxPageSize: 10000,
+		orders:      map[int64]HistoryOrder{},
+	}
+}
+
+// SetPageSize sets the number of orders requested in the first page.
+func (h *OrderHistory) SetPageSize(n int64) {
+	if n < 1 {
+		n = 1
+	}
+	h.pageSize = n
+}
+
+// SetMaxPageSize sets the largest number of orders requested in one page.
+// Keep it small enough for the response to fit the client's maximum body
+// size.
+func (h *OrderHistory) SetMaxPageSize(n int64) {
+	if n < 1 {
+		n = 1
+	}
+	h.maxPageSize = n
+}
+
+// Reset forgets all cached orders.
+func (h *OrderHistory) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.orders = map[int64]HistoryOrder{}
+	h.complete = false
+}
+
+// Orders returns an iterator over the orders matching filter, newest first:
+//
+//	it := history.Orders(fyb.HistoryFilter{From: start, To: end, Side: "B"})
+//	for it.Next() {
+//		order := it.Order()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (h *OrderHistory) Orders(filter HistoryFilter) *HistoryIterator {
+	return &HistoryIterator{
+		h:      h,
+		filter: filter,
+		limit:  min64(h.pageSize, h.maxPageSize),
+		seen:   map[int64]bool{},
+	}
+}
+
+// HistoryIterator iterates over the orders of an OrderHistory.
+type HistoryIterator struct {
+	h      *OrderHistory
+	filter HistoryFilter
+	limit  int64
+	seen   map[int64]bool
+	queue  []HistoryOrder
+	done   bool
+	cur    HistoryOrder
+	err    error
+}
+
+// Next advances to the next matching order, fetching pages as needed. It
+// returns false when the history is exhausted or on error.
+func (it *HistoryIterator) Next() bool {
+	for len(it.queue) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	it.cur, it.queue = it.queue[0], it.queue[1:]
+	return true
+}
+
+// Order returns the order found by the last call to Next.
+func (it *HistoryIterator) Order() HistoryOrder {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+// fetch requests the next page and queues its new matching orders.
+func (it *HistoryIterator) fetch() error {
+	res, err := it.h.api.GetOrderHistory(it.limit)
+	if err != nil {
+		return err
+	}
+	if res.Error != 0 {
+		return fmt.Errorf("FYB error %d: %s", res.Error, res.Msg)
+	}
+
+	h := it.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	reachedCache := false
+	oldest := int64(-1)
+	fresh := 0
+	for _, o := range res.Orders {
+		if it.seen[o.Ticket] {
+			h.orders[o.Ticket] = o
+			continue
+		}
+		fresh++
+		if _, ok := h.orders[o.Ticket]; ok && h.complete {
+			reachedCache = true
+		}
+		h.orders[o.Ticket] = o
+		it.seen[o.Ticket] = true
+		if oldest < 0 || o.DateCreated < oldest {
+			oldest = o.DateCreated
+		}
+		if it.filter.match(o) {
+			it.queue = append(it.queue, o)
+		}
+	}
+
+	switch {
+	case int64(len(res.Orders)) < it.limit:
+		// The page holds the whole history.
+		h.complete = true
+		it.done = true
+	case reachedCache:
+		// Everything older than this page is cached.
+		var rest []HistoryOrder
+		for ticket, o := range h.orders {
+			if !it.seen[ticket] && it.filter.match(o) {
+				rest = append(rest, o)
+			}
+		}
+		sort.Slice(rest, func(i, j int) bool {
+			if rest[i].DateCreated != rest[j].DateCreated {
+				return rest[i].DateCreated > rest[j].DateCreated
+			}
+			return rest[i].Ticket > rest[j].Ticket
+		})
+		it.queue = append(it.queue, rest...)
+		it.done = true
+	case !it.filter.From.IsZero() && oldest >= 0 && oldest < it.filter.From.Unix():
+		// Older pages only hold orders before From.
+		it.done = true
+	case fresh == 0 || it.limit >= h.maxPageSize:
+		// Larger pages are not allowed or FYB does not return them.
+		it.done = true
+		return ErrHistoryTruncated
+	default:
+		it.limit = min64(it.limit*2, h.maxPageSize)
+	}
+	return nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,103 @@
+package fyb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderHistory(t *testing.T) {
+	var mu sync.Mutex
+	var limits []int
+	var orders []HistoryOrder // newest first
+	add := func(ticket int64, side, status string) {
+		o := HistoryOrder{DateCreated: 1000 + ticket*10, Price: "S$3.00", Qty: "1BTC", Status: status, Type: side, Ticket: ticket}
+		orders = append([]HistoryOrder{o}, orders...)
+	}
+	for i := int64(1); i <= 5; i++ {
+		add(i, map[bool]string{true: "B", false: "S"}[i%2 == 1], "F")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		limits = append(limits, limit)
+		page := orders
+		if len(page) > limit {
+			page = page[:limit]
+		}
+		json.NewEncoder(w).Encode(OrderHistoryResponse{Orders: page})
+	}))
+	defer ts.Close()
+
+	tickets := func(it *HistoryIterator) []int64 {
+		var res []int64
+		for it.Next() {
+			res = append(res, it.Order().Ticket)
+		}
+		require.NoError(t, it.Err())
+		return res
+	}
+
+	history := NewOrderHistory(New(ts.URL, "key", "secret"))
+	history.SetPageSize(2)
+	require.Equal(t, []int64{5, 4, 3, 2, 1}, tickets(history.Orders(HistoryFilter{})))
+	require.Equal(t, []int{2, 4, 8}, limits)
+
+	// New orders are fetched, older ones come from the cache.
+	mu.Lock()
+	add(6, "S", "A")
+	limits = nil
+	mu.Unlock()
+	require.Equal(t, []int64{6, 5, 4, 3, 2, 1}, tickets(history.Orders(HistoryFilter{})))
+	require.Equal(t, []int{2}, limits)
+
+	filter := HistoryFilter{Side: "B", From: time.Unix(1020, 0), To: time.Unix(1060, 0)}
+	require.Equal(t, []int64{5, 3}, tickets(history.Orders(filter)))
+	require.Equal(t, []int64{6}, tickets(history.Orders(HistoryFilter{Status: "A"})))
+
+	// Without the cache, paging stops once orders are older than From.
+	history.Reset()
+	mu.Lock()
+	limits = nil
+	mu.Unlock()
+	require.Equal(t, []int64{6, 5}, tickets(history.Orders(HistoryFilter{From: time.Unix(1050, 0)})))
+	require.Equal(t, []int{2, 4}, limits)
+}
+
+func TestOrderHistoryTruncated(t *testing.T) {
+	var limits []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		limits = append(limits, limit)
+		var page []HistoryOrder
+		for ticket := int64(5); ticket >= 1 && len(page) < limit; ticket-- {
+			page = append(page, HistoryOrder{DateCreated: 1000 + ticket, Status: "F", Type: "B", Ticket: ticket})
+		}
+		json.NewEncoder(w).Encode(OrderHistoryResponse{Orders: page})
+	}))
+	defer ts.Close()
+
+	walk := func(history *OrderHistory) ([]int64, error) {
+		var res []int64
+		it := history.Orders(HistoryFilter{})
+		for it.Next() {
+			res = append(res, it.Order().Ticket)
+		}
+		return res, it.Err()
+	}
+
+	history := NewOrderHistory(New(ts.URL, "key", "secret"))
+	history.SetPageSize(2)
+	history.SetMaxPageSize(3)
+	tickets, err := walk(history)
+	require.Equal(t, ErrHistoryTruncated, err)
+	require.Equal(t, []int64{5, 4, 3}, tickets)
+	require.Equal(t, []int{2, 3}, limits)
+}
@@ -0,0 +1,42 @@
+package fyb
+
+import (
+	"regexp"
+)
+
+// Logger is the leveled, structured logger used by Client.
+// args are alternating key/value pairs, so *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger discards everything. It is the default so that importing
+// this package never writes to the application's log output.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+var (
+	redactHeaderRe = regexp.MustCompile(`(?mi)^(key|sig):.*$`)
+	redactEmailRe  = regexp.MustCompile(`("email"\s*:\s*")[^"]*(")`)
+)
+
+const redacted = "[REDACTED]"
+
+// redactDump hides the API key, signature and account email in a dumped
+// HTTP request or response.
+func redactDump(dump []byte) string {
+	dump = redactHeaderRe.ReplaceAll(dump, []byte("${1}: "+redacted+"\r"))
+	return redactBody(dump)
+}
+
+// redactBody hides the account email in a response body.
+func redactBody(body []byte) string {
+	return string(redactEmailRe.ReplaceAll(body, []byte("${1}"+redacted+"${2}")))
+}
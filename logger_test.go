@@ -0,0 +1,31 @@
+package fyb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactDump(t *testing.T) {
+	dump := "POST /api/SGD/getaccinfo HTTP/1.1\r\n" +
+		"Host: fybsg.com\r\n" +
+		"Key: mykey\r\n" +
+		"Sig: abcdef\r\n" +
+		"\r\n" +
+		`{"accNo":1234,"email":"someone@example.com","error":0}`
+
+	out := redactDump([]byte(dump))
+	require.NotContains(t, out, "mykey")
+	require.NotContains(t, out, "abcdef")
+	require.NotContains(t, out, "someone@example.com")
+	require.Contains(t, out, "Key: "+redacted+"\r\n")
+	require.Contains(t, out, "Sig: "+redacted+"\r\n")
+	require.True(t, strings.HasPrefix(out, "POST /api/SGD/getaccinfo"))
+}
+
+func TestEndpointName(t *testing.T) {
+	require.Equal(t, "trades.json", endpointName("trades.json?since=10"))
+	require.Equal(t, "getaccinfo", endpointName("getaccinfo"))
+	require.Equal(t, "ticker.json", endpointName("https://fybsg.com/api/SGD/ticker.json"))
+}
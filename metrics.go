@@ -0,0 +1,217 @@
+package fyb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Error classes reported to Metrics. A successful call has an empty class.
+const (
+	ErrorClassTimeout = "timeout" // no response within the client timeout
+	ErrorClassNetwork = "network" // transport or body read failure
+	ErrorClassAuth    = "auth"    // missing or rejected API key
+	ErrorClassClient  = "client"  // other non-200 status below 500
+	ErrorClassServer  = "server"  // 5xx status
+)
+
+// Metrics receives measurements for every API call made by Client.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called once per HTTP attempt. class is empty on success.
+	ObserveRequest(endpoint string, latency time.Duration, class string)
+	// ObserveThrottleWait reports how long the call waited for the rate limiter.
+	ObserveThrottleWait(endpoint string, wait time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(endpoint string, latency time.Duration, class string) {}
+func (nopMetrics) ObserveThrottleWait(endpoint string, wait time.Duration)             {}
+
+// errorClass classifies the outcome of a request.
+func errorClass(res apiResponse) string {
+	switch {
+	case res.err == errTimeout:
+		return ErrorClassTimeout
+	case res.err == errNoCredentials:
+		return ErrorClassAuth
+	case res.err == errBodyTooLarge:
+		return ErrorClassClient
+	case res.status == http.StatusUnauthorized || res.status == http.StatusForbidden:
+		return ErrorClassAuth
+	case res.status >= 500:
+		return ErrorClassServer
+	case res.err != nil && res.status != 0 && res.status != http.StatusOK:
+		return ErrorClassClient
+	case res.err != nil:
+		return ErrorClassNetwork
+	case isKeyPermissionError(res.body):
+		return ErrorClassAuth
+	}
+	return ""
+}
+
+// isKeyPermissionError reports whether body is FYB's {"error": "..."}
+// answer to a bad key, which comes back with status 200.
+func isKeyPermissionError(body []byte) bool {
+	if !bytes.Contains(body, []byte(`"error"`)) {
+		return false
+	}
+	kperr := KeyPermissionErrorResponse{}
+	return json.Unmarshal(body, &kperr) == nil && kperr.Error != ""
+}
+
+var (
+	latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	waitBuckets    = []float64{0.001, 0.01, 0.05, 0.1, 0.2, 0.5, 1, 2, 5}
+)
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+type errorKey struct {
+	endpoint string
+	class    string
+}
+
+// PrometheusMetrics is a Metrics implementation that serves the collected
+// values in the Prometheus text exposition format:
+//
+//	m := fyb.NewPrometheusMetrics()
+//	client.SetMetrics(m)
+//	http.Handle("/metrics", m)
+type PrometheusMetrics struct {
+	mu       sync.Mutex
+	requests map[string]uint64
+	errors   map[errorKey]uint64
+	latency  map[string]*histogram
+	wait     map[string]*histogram
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		requests: map[string]uint64{},
+		errors:   map[errorKey]uint64{},
+		latency:  map[string]*histogram{},
+		wait:     map[string]*histogram{},
+	}
+}
+
+// ObserveRequest implements Metrics.
+func (m *PrometheusMetrics) ObserveRequest(endpoint string, latency time.Duration, class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[endpoint]++
+	if class != "" {
+		m.errors[errorKey{endpoint, class}]++
+	}
+	h, ok := m.latency[endpoint]
+	if !ok {
+		h = newHistogram(latencyBuckets)
+		m.latency[endpoint] = h
+	}
+	h.observe(latency.Seconds())
+}
+
+// ObserveThrottleWait implements Metrics.
+func (m *PrometheusMetrics) ObserveThrottleWait(endpoint string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.wait[endpoint]
+	if !ok {
+		h = newHistogram(waitBuckets)
+		m.wait[endpoint] = h
+	}
+	h.observe(wait.Seconds())
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	m.write(&buf)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+func (m *PrometheusMetrics) write(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf.WriteString("# HELP fyb_requests_total FYB API requests by endpoint.\n")
+	buf.WriteString("# TYPE fyb_requests_total counter\n")
+	for _, ep := range sortedKeys(m.requests) {
+		fmt.Fprintf(buf, "fyb_requests_total{endpoint=%q} %d\n", ep, m.requests[ep])
+	}
+
+	errKeys := make([]errorKey, 0, len(m.errors))
+	for k := range m.errors {
+		errKeys = append(errKeys, k)
+	}
+	sort.Slice(errKeys, func(i, j int) bool {
+		if errKeys[i].endpoint != errKeys[j].endpoint {
+			return errKeys[i].endpoint < errKeys[j].endpoint
+		}
+		return errKeys[i].class < errKeys[j].class
+	})
+	buf.WriteString("# HELP fyb_request_errors_total Failed FYB API requests by endpoint and error class.\n")
+	buf.WriteString("# TYPE fyb_request_errors_total counter\n")
+	for _, k := range errKeys {
+		fmt.Fprintf(buf, "fyb_request_errors_total{endpoint=%q,class=%q} %d\n", k.endpoint, k.class, m.errors[k])
+	}
+
+	writeHistograms(buf, "fyb_request_duration_seconds", "FYB API request latency.", m.latency)
+	writeHistograms(buf, "fyb_throttle_wait_seconds", "Time spent waiting for the client rate limiter.", m.wait)
+}
+
+func writeHistograms(buf *bytes.Buffer, name, help string, hs map[string]*histogram) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buf, "# TYPE %s histogram\n", name)
+	eps := make([]string, 0, len(hs))
+	for ep := range hs {
+		eps = append(eps, ep)
+	}
+	sort.Strings(eps)
+	for _, ep := range eps {
+		h := hs[ep]
+		for i, le := range h.buckets {
+			fmt.Fprintf(buf, "%s_bucket{endpoint=%q,le=%q} %d\n", name, ep, strconv.FormatFloat(le, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(buf, "%s_bucket{endpoint=%q,le=\"+Inf\"} %d\n", name, ep, h.count)
+		fmt.Fprintf(buf, "%s_sum{endpoint=%q} %g\n", name, ep, h.sum)
+		fmt.Fprintf(buf, "%s_count{endpoint=%q} %d\n", name, ep, h.count)
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package fyb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorClass(t *testing.T) {
+	require.Equal(t, "", errorClass(apiResponse{body: []byte(`{"error":0,"msg":"success"}`), status: 200}))
+	require.Equal(t, ErrorClassAuth, errorClass(apiResponse{body: []byte(`{"error":"No Key with that name found"}`), status: 200}))
+	require.Equal(t, ErrorClassAuth, errorClass(apiResponse{err: errNoCredentials}))
+	require.Equal(t, ErrorClassTimeout, errorClass(apiResponse{err: errTimeout}))
+	require.Equal(t, ErrorClassServer, errorClass(apiResponse{status: 502, err: errors.New("502 Bad Gateway")}))
+	require.Equal(t, ErrorClassClient, errorClass(apiResponse{status: 404, err: errors.New("404 Not Found")}))
+}
+
+func TestPrometheusMetricsCountsAttempts(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ask":1,"bid":1,"last":1,"vol":1}`))
+	}))
+	defer ts.Close()
+
+	m := NewPrometheusMetrics()
+	api := New(ts.URL, "", "")
+	api.SetMetrics(m)
+	// Every attempt of a retrying middleware is counted.
+	api.Use(func(next Handler) Handler {
+		return func(call *Call) ([]byte, error) {
+			for attempt := 0; ; attempt++ {
+				body, err := next(call)
+				if err == nil || attempt == 2 {
+					return body, err
+				}
+			}
+		}
+	})
+	_, err := api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	require.Contains(t, out, `fyb_requests_total{endpoint="tickerdetailed.json"} 3`)
+	require.Contains(t, out, `fyb_request_errors_total{endpoint="tickerdetailed.json",class="server"} 2`)
+	require.Contains(t, out, `fyb_request_duration_seconds_count{endpoint="tickerdetailed.json"} 3`)
+}
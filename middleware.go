@@ -0,0 +1,105 @@
+package fyb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Call describes a single FYB API call as it passes through the
+// middleware chain.
+type Call struct {
+	// Context is used for the HTTP request. Middleware may replace it,
+	// e.g. to carry a tracing span or a deadline.
+	Context context.Context
+
+	Method   string            // HTTP method, "GET" or "POST"
+	Resource string            // path relative to the API base URL, with query
+	Endpoint string            // Resource without query string, e.g. "trades.json"
+	Payload  map[string]string // form values, before timestamp and signing
+	Auth     bool              // whether the call is signed with the API key
+	Ticket   string            // order ticket the call refers to, if any
+
+	// Header holds extra headers sent with the request.
+	Header http.Header
+
+	// Stream is set for calls whose response is decoded as it is read,
+	// such as StreamTradeHistory. Their innermost Handler returns no body
+	// and leaves the open response body in StreamBody instead.
+	Stream     bool
+	StreamBody io.ReadCloser
+}
+
+// Handler performs a Call and returns the raw response body.
+type Handler func(call *Call) ([]byte, error)
+
+// Middleware wraps a Handler. It can inspect or modify the Call before
+// passing it on, look at the result, or answer without calling next at all
+// (e.g. from a cache).
+//
+// A tracing middleware might look like:
+//
+//	func tracing(next fyb.Handler) fyb.Handler {
+//		return func(call *fyb.Call) ([]byte, error) {
+//			ctx, span := tracer.Start(call.Context, "fyb "+call.Endpoint)
+//			defer span.End()
+//			span.SetAttributes(attribute.String("fyb.ticket", call.Ticket))
+//			call.Context = ctx
+//			body, err := next(call)
+//			if err != nil {
+//				span.RecordError(err)
+//			}
+//			return body, err
+//		}
+//	}
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the client. The first middleware added is the
+// outermost; the chain wraps the whole call, so a middleware may retry it
+// by calling next again.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// ResponseHook returns a Middleware that passes the raw response body of
+// every call to fn, e.g. to archive responses or debug parsing. body is
+// empty if no response was received and must not be modified. For a
+// streamed call, fn is called when the body is closed, with the part of it
+// that was read.
+func ResponseHook(fn func(call *Call, body []byte)) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call) ([]byte, error) {
+			body, err := next(call)
+			if call.Stream && call.StreamBody != nil {
+				call.StreamBody = &hookedBody{ReadCloser: call.StreamBody, call: call, fn: fn}
+				return body, err
+			}
+			fn(call, body)
+			return body, err
+		}
+	}
+}
+
+// hookedBody keeps what is read from a streamed body and passes it to a
+// ResponseHook when it is closed.
+type hookedBody struct {
+	io.ReadCloser
+	call *Call
+	fn   func(call *Call, body []byte)
+	buf  bytes.Buffer
+	once sync.Once
+}
+
+func (b *hookedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *hookedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.fn(b.call, b.buf.Bytes()) })
+	return err
+}
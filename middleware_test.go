@@ -0,0 +1,69 @@
+package fyb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	var gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Audit")
+		w.Write([]byte(`{"error":0}`))
+	}))
+	defer ts.Close()
+
+	var order []string
+	api := New(ts.URL, "key", "secret")
+	api.Use(
+		func(next Handler) Handler {
+			return func(call *Call) ([]byte, error) {
+				order = append(order, "outer:"+call.Endpoint+":"+call.Ticket)
+				call.Header.Set("X-Audit", "yes")
+				return next(call)
+			}
+		},
+		func(next Handler) Handler {
+			return func(call *Call) ([]byte, error) {
+				order = append(order, "inner")
+				return next(call)
+			}
+		},
+	)
+	res, err := api.CancelPendingOrder(42)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), res.Error)
+	require.Equal(t, []string{"outer:cancelpendingorder:42", "inner"}, order)
+	require.Equal(t, "yes", gotHeader)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	api := New("http://127.0.0.1:1", "", "")
+	api.Use(func(next Handler) Handler {
+		return func(call *Call) ([]byte, error) {
+			return []byte(`{"ask":"1.5","bid":"1.4","last":"1.45","vol":"10"}`), nil
+		}
+	})
+	ticker, err := api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, "1.5", ticker.Ask.String())
+}
+
+func TestResponseHook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ask":"1.5","bid":"1.4","last":"1.45","vol":"10"}`))
+	}))
+	defer ts.Close()
+
+	var raw string
+	api := New(ts.URL, "", "")
+	api.Use(ResponseHook(func(call *Call, body []byte) {
+		raw = call.Endpoint + " " + string(body)
+	}))
+	_, err := api.GetTicker()
+	require.NoError(t, err)
+	require.Equal(t, `tickerdetailed.json {"ask":"1.5","bid":"1.4","last":"1.45","vol":"10"}`, raw)
+}
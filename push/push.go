@@ -0,0 +1,289 @@
+// Package push fans FYB order book and trade updates out to WebSocket
+// subscribers. A single fyb.Tracker polls FYB; every subscriber gets a
+// snapshot on connect followed by deltas.
+//
+// Every message carries a sequence number. The snapshot has the sequence
+// number of the last update it includes and each following message
+// increments it by one, so a client that sees a gap should reconnect to get
+// a fresh snapshot. The server skips a sequence number itself when it may
+// have missed trades from the tracker, which drops batches for a reader
+// that falls behind.
+//
+//	{"type":"snapshot","seq":41,"book":{"bids":[...],"asks":[...]},"trades":[...]}
+//	{"type":"book","seq":42,"bids":[{"price":"1005.17","amount":"0"}],"asks":[...]}
+//	{"type":"trades","seq":43,"trades":[{"tid":2218612,"date":1387975230,"price":"1012.48","amount":"0.5"}]}
+//
+// In "book" deltas an amount of "0" removes the price level.
+package push
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	recentTrades = 50
+	clientBuffer = 256
+)
+
+// Level is one price level of the order book.
+type Level struct {
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Book is an order book snapshot, best prices first.
+type Book struct {
+	Bids []Level `json:"bids"`
+	Asks []Level `json:"asks"`
+}
+
+// Trade is a public trade.
+type Trade struct {
+	TID    int64           `json:"tid"`
+	Date   int64           `json:"date"`
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Message is sent to subscribers. Type is "snapshot", "book" or "trades".
+type Message struct {
+	Type   string  `json:"type"`
+	Seq    uint64  `json:"seq"`
+	Book   *Book   `json:"book,omitempty"`
+	Bids   []Level `json:"bids,omitempty"`
+	Asks   []Level `json:"asks,omitempty"`
+	Trades []Trade `json:"trades,omitempty"`
+}
+
+// Server is an http.Handler upgrading requests to WebSocket subscriptions.
+type Server struct {
+	tracker  *fyb.Tracker
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	seq     uint64
+	book    Book
+	trades  []Trade
+	clients map[chan *Message]struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewServer returns a Server fed by tracker. The tracker must be started
+// separately; call Start to begin forwarding its updates.
+func NewServer(tracker *fyb.Tracker) *Server {
+	return &Server{
+		tracker: tracker,
+		book:    Book{Bids: []Level{}, Asks: []Level{}},
+		clients: map[chan *Message]struct{}{},
+	}
+}
+
+// SetCheckOrigin overrides the origin check for WebSocket upgrades. By
+// default only requests without an Origin header or from a page on the
+// same host are accepted.
+func (s *Server) SetCheckOrigin(check func(r *http.Request) bool) {
+	s.upgrader.CheckOrigin = check
+}
+
+// Start begins forwarding tracker updates to subscribers.
+func (s *Server) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.loop(s.stop, s.done)
+}
+
+// Stop stops forwarding updates and disconnects all subscribers.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+
+	s.mu.Lock()
+	for ch := range s.clients {
+		close(ch)
+		delete(s.clients, ch)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Server) loop(stop, done chan struct{}) {
+	defer close(done)
+	books, unsubBooks := s.tracker.SubscribeBook()
+	defer unsubBooks()
+	trades, unsubTrades := s.tracker.SubscribeTrades()
+	defer unsubTrades()
+	for {
+		select {
+		case <-stop:
+			return
+		case book := <-books:
+			s.updateBook(book)
+		case batch := <-trades:
+			// The tracker drops batches while our buffer is full, so a
+			// full buffer means trades may have been lost.
+			s.updateTrades(batch, len(trades)+1 >= cap(trades))
+		}
+	}
+}
+
+func toLevels(levels []fyb.PriceAmount) []Level {
+	res := make([]Level, 0, len(levels))
+	for _, l := range levels {
+		res = append(res, Level{l.Price, l.Amount})
+	}
+	return res
+}
+
+// diff returns the levels of next that are new or changed compared to
+// prev, plus removed levels with a zero amount.
+func diff(prev, next []Level) []Level {
+	old := map[string]decimal.Decimal{}
+	for _, l := range prev {
+		old[l.Price.String()] = l.Amount
+	}
+	var res []Level
+	for _, l := range next {
+		key := l.Price.String()
+		if amount, ok := old[key]; !ok || !amount.Equal(l.Amount) {
+			res = append(res, l)
+		}
+		delete(old, key)
+	}
+	for _, l := range prev {
+		if _, ok := old[l.Price.String()]; ok {
+			res = append(res, Level{l.Price, decimal.Zero})
+		}
+	}
+	return res
+}
+
+func (s *Server) updateBook(book fyb.OrderBook) {
+	next := Book{Bids: toLevels(book.Bids), Asks: toLevels(book.Asks)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bids, asks := diff(s.book.Bids, next.Bids), diff(s.book.Asks, next.Asks)
+	s.book = next
+	if len(bids) == 0 && len(asks) == 0 {
+		return
+	}
+	s.seq++
+	s.broadcast(&Message{Type: "book", Seq: s.seq, Bids: bids, Asks: asks})
+}
+
+// updateTrades broadcasts batch. If trades may have been lost before it,
+// a sequence number is skipped so that clients resubscribe.
+func (s *Server) updateTrades(batch fyb.Trades, lost bool) {
+	if len(batch) == 0 {
+		return
+	}
+	trades := make([]Trade, 0, len(batch))
+	for _, t := range batch {
+		trades = append(trades, Trade{t.TID, t.Date, t.Price, t.Amount})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trades = append(s.trades, trades...)
+	if len(s.trades) > recentTrades {
+		s.trades = s.trades[len(s.trades)-recentTrades:]
+	}
+	if lost {
+		s.seq++
+	}
+	s.seq++
+	s.broadcast(&Message{Type: "trades", Seq: s.seq, Trades: trades})
+}
+
+// broadcast queues m for every client. Clients whose buffer is full are
+// dropped; they will reconnect and start again from a snapshot. Called with
+// s.mu held.
+func (s *Server) broadcast(m *Message) {
+	for ch := range s.clients {
+		select {
+		case ch <- m:
+		default:
+			close(ch)
+			delete(s.clients, ch)
+		}
+	}
+}
+
+// subscribe registers a client and returns its queue, which starts with a
+// snapshot consistent with the following deltas.
+func (s *Server) subscribe() chan *Message {
+	ch := make(chan *Message, clientBuffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	book := Book{
+		Bids: append([]Level{}, s.book.Bids...),
+		Asks: append([]Level{}, s.book.Asks...),
+	}
+	ch <- &Message{Type: "snapshot", Seq: s.seq, Book: &book, Trades: append([]Trade{}, s.trades...)}
+	s.clients[ch] = struct{}{}
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[ch]; ok {
+		close(ch)
+		delete(s.clients, ch)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams messages until
+// the client goes away.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	// Read and discard client messages so that close frames are handled.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case m, ok := <-ch:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "resubscribe"))
+				return
+			}
+			if err := conn.WriteJSON(m); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package push
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	fyb "github.com/rakd/go-fyb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotAndDeltas(t *testing.T) {
+	var mu sync.Mutex
+	book := `{"asks":[[1012.48,0.5],[1020,1.25]],"bids":[[1005.17,0.3]]}`
+	trades := `[{"amount":"0.01","date":1387975210,"price":"1011.00","tid":100}]`
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/orderbook.json":
+			w.Write([]byte(book))
+		case "/trades.json":
+			w.Write([]byte(trades))
+		}
+	}))
+	defer api.Close()
+
+	tracker := fyb.NewTracker(fyb.New(api.URL, "", ""), time.Hour, 0)
+	srv := NewServer(tracker)
+	srv.Start()
+	defer srv.Stop()
+	tracker.Poll()
+
+	ws := httptest.NewServer(srv)
+	defer ws.Close()
+
+	// Wait for the first book to reach the server.
+	require.Eventually(t, func() bool {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return srv.seq == 1
+	}, time.Second, 10*time.Millisecond)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ws.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var m Message
+	require.NoError(t, conn.ReadJSON(&m))
+	require.Equal(t, "snapshot", m.Type)
+	require.Equal(t, uint64(1), m.Seq)
+	require.Len(t, m.Book.Asks, 2)
+
+	mu.Lock()
+	book = `{"asks":[[1012.48,0.7]],"bids":[[1005.17,0.3]]}`
+	trades = `[{"amount":"0.5","date":1387975230,"price":"1012.48","tid":101}]`
+	mu.Unlock()
+	tracker.Poll()
+
+	require.NoError(t, conn.ReadJSON(&m))
+	require.Equal(t, "book", m.Type)
+	require.Equal(t, uint64(2), m.Seq)
+	require.Empty(t, m.Bids)
+	require.Len(t, m.Asks, 2)
+	require.Equal(t, "0.7", m.Asks[0].Amount.String())
+	require.Equal(t, "1020", m.Asks[1].Price.String())
+	require.True(t, m.Asks[1].Amount.IsZero())
+
+	require.NoError(t, conn.ReadJSON(&m))
+	require.Equal(t, "trades", m.Type)
+	require.Equal(t, uint64(3), m.Seq)
+	require.Equal(t, int64(101), m.Trades[0].TID)
+}
+
+func TestSkipsSequenceOnLostTrades(t *testing.T) {
+	srv := NewServer(nil)
+	ch := srv.subscribe()
+	<-ch
+	srv.updateTrades(fyb.Trades{{TID: 1}}, false)
+	srv.updateTrades(fyb.Trades{{TID: 3}}, true)
+	require.Equal(t, uint64(1), (<-ch).Seq)
+	require.Equal(t, uint64(3), (<-ch).Seq, "a gap tells clients to resubscribe")
+}
+
+func TestRejectsOtherOrigins(t *testing.T) {
+	ws := httptest.NewServer(NewServer(nil))
+	defer ws.Close()
+	url := "ws" + strings.TrimPrefix(ws.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://evil.example"}})
+	require.Error(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {ws.URL}})
+	require.NoError(t, err)
+	conn.Close()
+}
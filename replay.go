@@ -0,0 +1,193 @@
+package fyb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fixture is one recorded request/response pair, stored as a JSON file.
+type Fixture struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`   // URL path and query, e.g. "/api/SGD/trades.json?since=1"
+	Form   string      `json:"form"`   // request form data without the timestamp
+	Header http.Header `json:"header"` // request headers, key and sig redacted
+	Status int         `json:"status"`
+	Body   string      `json:"body"` // response body, account email redacted
+}
+
+func (f *Fixture) key() string {
+	return f.Method + " " + f.Path + " " + f.Form
+}
+
+// fixtureForm returns the form data of req without the timestamp, so that
+// signed requests match across runs. The request body is restored.
+func fixtureForm(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return "", err
+	}
+	values.Del("timestamp")
+	return values.Encode(), nil
+}
+
+// RecordingTransport is an http.RoundTripper that passes requests on to an
+// underlying transport and saves every exchange as a Fixture file in Dir.
+// Use it with Fyb.SetTransport to capture fixtures from the real API.
+type RecordingTransport struct {
+	Dir       string
+	Transport http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecordingTransport returns a RecordingTransport writing to dir. If next
+// is nil, http.DefaultTransport is used.
+func NewRecordingTransport(dir string, next http.RoundTripper) (*RecordingTransport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingTransport{Dir: dir, Transport: next, seq: len(existing)}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	form, err := fixtureForm(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	header := http.Header{}
+	for k, v := range req.Header {
+		switch http.CanonicalHeaderKey(k) {
+		case "Key", "Sig":
+			header[k] = []string{redacted}
+		default:
+			header[k] = v
+		}
+	}
+	fixture := Fixture{
+		Method: req.Method,
+		Path:   req.URL.RequestURI(),
+		Form:   form,
+		Header: header,
+		Status: resp.StatusCode,
+		Body:   redactBody(body),
+	}
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fixture); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	name := fmt.Sprintf("%04d-%s-%s.json", t.seq, req.Method, strings.TrimSuffix(path.Base(req.URL.Path), ".json"))
+	if err := ioutil.WriteFile(filepath.Join(t.Dir, name), data.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplayTransport is an http.RoundTripper that answers requests from
+// fixtures recorded by RecordingTransport, matched by method, path, query
+// and form payload. Identical requests get the recorded responses in order;
+// once exhausted the last one is repeated.
+type ReplayTransport struct {
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+}
+
+// NewReplayTransport loads all fixtures in dir.
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	t := &ReplayTransport{fixtures: map[string][]*Fixture{}}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fixture := &Fixture{}
+		if err := json.Unmarshal(data, fixture); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		t.fixtures[fixture.key()] = append(t.fixtures[fixture.key()], fixture)
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	form, err := fixtureForm(req)
+	if err != nil {
+		return nil, err
+	}
+	probe := Fixture{Method: req.Method, Path: req.URL.RequestURI(), Form: form}
+
+	t.mu.Lock()
+	queue := t.fixtures[probe.key()]
+	if len(queue) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no fixture for %s %s form=%q", probe.Method, probe.Path, probe.Form)
+	}
+	fixture := queue[0]
+	if len(queue) > 1 {
+		t.fixtures[probe.key()] = queue[1:]
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(fixture.Body)),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       req,
+	}, nil
+}
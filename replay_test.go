@@ -0,0 +1,49 @@
+package fyb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTransportRedacts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"accNo":1,"email":"me@example.com","error":0}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "fyb-record")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rec, err := NewRecordingTransport(dir, nil)
+	require.NoError(t, err)
+	api := New(ts.URL, "mykey", "mysecret")
+	api.SetTransport(rec)
+	info, err := api.GetAccountInfo()
+	require.NoError(t, err)
+	require.Equal(t, "me@example.com", info.Email)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "mykey")
+	require.NotContains(t, string(data), "me@example.com")
+
+	replay, err := NewReplayTransport(dir)
+	require.NoError(t, err)
+	api.SetTransport(replay)
+	info, err = api.GetAccountInfo()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), info.AccNo)
+
+	_, err = api.GetOrderHistory(5)
+	require.Error(t, err, "no fixture recorded")
+}
@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	fyb "github.com/rakd/go-fyb"
+	"github.com/shopspring/decimal"
+)
+
+// Maker is a simple market maker: it quotes Qty on both sides of the mid
+// price, Spread apart, and shifts its fair value by Skew per BTC of
+// position so inventory tends back towards zero.
+type Maker struct {
+	Base
+	Spread decimal.Decimal
+	Qty    decimal.Decimal
+	Skew   decimal.Decimal
+}
+
+// OnBook implements Strategy.
+func (mk *Maker) OnBook(r *Runtime, book fyb.OrderBook) error {
+	mid, ok := Mid(book)
+	if !ok {
+		return r.CancelQuotes()
+	}
+	fair := mid.Sub(r.Position().Mul(mk.Skew))
+	return r.QuoteAround(fair, mk.Spread, mk.Qty)
+}
@@ -0,0 +1,436 @@
+// Package strategy runs market-making strategies on FYB. A Runtime feeds a
+// Strategy with order book and trade updates from a fyb.Tracker, fills from
+// an execution.Manager and a periodic timer, and manages its two-sided
+// quotes within inventory and loss limits.
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/execution"
+	"github.com/shopspring/decimal"
+)
+
+// Strategy reacts to market events, usually by calling r.Quote. All
+// callbacks are called from the goroutine running the Runtime. Errors are
+// passed to the Runtime's OnError.
+type Strategy interface {
+	OnBook(r *Runtime, book fyb.OrderBook) error
+	OnTrade(r *Runtime, trades fyb.Trades) error
+	OnFill(r *Runtime, fill execution.Fill) error
+	OnTimer(r *Runtime, now time.Time) error
+}
+
+// Base implements Strategy with no-ops, for embedding.
+type Base struct{}
+
+// OnBook implements Strategy.
+func (Base) OnBook(r *Runtime, book fyb.OrderBook) error { return nil }
+
+// OnTrade implements Strategy.
+func (Base) OnTrade(r *Runtime, trades fyb.Trades) error { return nil }
+
+// OnFill implements Strategy.
+func (Base) OnFill(r *Runtime, fill execution.Fill) error { return nil }
+
+// OnTimer implements Strategy.
+func (Base) OnTimer(r *Runtime, now time.Time) error { return nil }
+
+// Quote is one side of a two-sided quote. A zero Qty quotes nothing.
+type Quote struct {
+	Price decimal.Decimal
+	Qty   decimal.Decimal
+}
+
+// Config configures a Runtime. Inventory limits are relative to the
+// position when the Runtime was created; zero means no limit.
+type Config struct {
+	// MaxLong is the largest BTC position bids may build.
+	MaxLong decimal.Decimal
+	// MaxShort is the largest BTC position asks may sell down.
+	MaxShort decimal.Decimal
+	// MaxLoss kills the runtime when the P&L, marked at the mid price,
+	// falls below -MaxLoss.
+	MaxLoss decimal.Decimal
+	// Tolerance is how far a quote's price may move before the resting
+	// order is replaced.
+	Tolerance decimal.Decimal
+	// Interval is the time between fill syncs and OnTimer calls.
+	// Default is 1s.
+	Interval time.Duration
+}
+
+// ErrKilled is returned once the kill switch has been pulled.
+var ErrKilled = errors.New("strategy killed")
+
+// maxKillRetry caps the backoff between cancel attempts of Kill.
+const maxKillRetry = 10 * time.Second
+
+// Runtime drives a Strategy and manages its quotes.
+type Runtime struct {
+	s       Strategy
+	m       *execution.Manager
+	tracker *fyb.Tracker
+	cfg     Config
+
+	// OnError, if set, is called with errors from strategy callbacks and
+	// fill syncs.
+	OnError func(err error)
+	// OnKill, if set, is called when the kill switch is pulled.
+	OnKill func(reason string)
+
+	quoting sync.Mutex // serializes quote changes
+
+	mu       sync.Mutex
+	bid, ask int64 // tickets of the resting quotes
+	ours     map[int64]bool
+	position decimal.Decimal
+	cash     decimal.Decimal
+	book     fyb.OrderBook
+	pending  []execution.Fill
+	killed   bool
+	reason   string
+	killc    chan struct{} // closed once the kill switch has cleared the book
+
+	killRetry time.Duration
+}
+
+// New returns a Runtime running s on the market data from tracker and
+// placing quotes through m.
+func New(s Strategy, m *execution.Manager, tracker *fyb.Tracker, cfg Config) *Runtime {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	r := &Runtime{
+		s:         s,
+		m:         m,
+		tracker:   tracker,
+		cfg:       cfg,
+		ours:      map[int64]bool{},
+		killc:     make(chan struct{}),
+		killRetry: 100 * time.Millisecond,
+	}
+	m.OnFill(r.fill)
+	return r
+}
+
+// Run drives the strategy until ctx is cancelled or the runtime is killed,
+// then cancels the quotes. The tracker must be started separately.
+func (r *Runtime) Run(ctx context.Context) error {
+	books, unsubBooks := r.tracker.SubscribeBook()
+	defer unsubBooks()
+	trades, unsubTrades := r.tracker.SubscribeTrades()
+	defer unsubTrades()
+	timer := time.NewTicker(r.cfg.Interval)
+	defer timer.Stop()
+	if book, ok := r.tracker.Book(); ok {
+		r.onBook(book)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if err := r.CancelQuotes(); err != nil {
+				r.error(err)
+			}
+			return ctx.Err()
+		case <-r.killc:
+			return ErrKilled
+		case book := <-books:
+			r.onBook(book)
+		case batch := <-trades:
+			r.onTrades(batch)
+		case now := <-timer.C:
+			r.onTimer(now)
+		}
+	}
+}
+
+func (r *Runtime) onBook(book fyb.OrderBook) {
+	r.mu.Lock()
+	r.book = book
+	r.mu.Unlock()
+	if r.checkLoss() {
+		return
+	}
+	r.error(r.s.OnBook(r, book))
+}
+
+func (r *Runtime) onTrades(trades fyb.Trades) {
+	if r.Killed() {
+		return
+	}
+	r.error(r.s.OnTrade(r, trades))
+}
+
+// onTimer syncs fills, passes them to the strategy and calls OnTimer.
+func (r *Runtime) onTimer(now time.Time) {
+	if r.Killed() {
+		return
+	}
+	r.error(r.m.Sync())
+	r.mu.Lock()
+	fills := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if r.checkLoss() {
+		return
+	}
+	for _, f := range fills {
+		r.error(r.s.OnFill(r, f))
+	}
+	r.error(r.s.OnTimer(r, now))
+}
+
+// fill is the Manager's fill handler. It updates the inventory right away
+// and queues the fill for the strategy.
+func (r *Runtime) fill(f execution.Fill) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.ours[f.Order.Ticket] {
+		return
+	}
+	value := f.Qty.Mul(f.Price)
+	if f.Order.Side == execution.Buy {
+		r.position = r.position.Add(f.Qty)
+		r.cash = r.cash.Sub(value)
+	} else {
+		r.position = r.position.Sub(f.Qty)
+		r.cash = r.cash.Add(value)
+	}
+	r.pending = append(r.pending, f)
+}
+
+// checkLoss pulls the kill switch if the loss limit is exceeded.
+func (r *Runtime) checkLoss() bool {
+	if r.Killed() {
+		return true
+	}
+	if !r.cfg.MaxLoss.IsPositive() {
+		return false
+	}
+	if pnl, ok := r.PnL(); ok && pnl.LessThan(r.cfg.MaxLoss.Neg()) {
+		r.Kill("loss limit exceeded: " + pnl.String())
+		return true
+	}
+	return false
+}
+
+func (r *Runtime) error(err error) {
+	if err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+// Book returns the last order book.
+func (r *Runtime) Book() fyb.OrderBook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.book
+}
+
+// Position returns the BTC bought less the BTC sold by the quotes.
+func (r *Runtime) Position() decimal.Decimal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.position
+}
+
+// PnL returns the P&L of the quotes' fills, with the position marked at
+// the mid price. ok is false while the book is one-sided.
+func (r *Runtime) PnL() (pnl decimal.Decimal, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mid, ok := Mid(r.book)
+	if !ok {
+		return decimal.Zero, false
+	}
+	return r.cash.Add(r.position.Mul(mid)), true
+}
+
+// Quotes returns the resting bid and ask. A side that is not quoted is
+// returned with a zero ticket.
+func (r *Runtime) Quotes() (bid, ask execution.Order) {
+	r.mu.Lock()
+	bt, at := r.bid, r.ask
+	r.mu.Unlock()
+	bid, _ = r.m.Order(bt)
+	ask, _ = r.m.Order(at)
+	return bid, ask
+}
+
+// Quote places or replaces the two-sided quote. Sizes are reduced to stay
+// within the inventory limits. A resting quote is kept while its price is
+// within Tolerance of the new one.
+func (r *Runtime) Quote(bid, ask Quote) error {
+	r.quoting.Lock()
+	defer r.quoting.Unlock()
+	if r.Killed() {
+		return ErrKilled
+	}
+	r.mu.Lock()
+	position := r.position
+	r.mu.Unlock()
+	if r.cfg.MaxLong.IsPositive() {
+		bid.Qty = decimal.Min(bid.Qty, r.cfg.MaxLong.Sub(position))
+	}
+	if r.cfg.MaxShort.IsPositive() {
+		ask.Qty = decimal.Min(ask.Qty, r.cfg.MaxShort.Add(position))
+	}
+	if err := r.requote(execution.Buy, bid, &r.bid); err != nil {
+		return err
+	}
+	return r.requote(execution.Sell, ask, &r.ask)
+}
+
+// QuoteAround quotes qty on both sides, spread apart and centred on fair.
+func (r *Runtime) QuoteAround(fair, spread, qty decimal.Decimal) error {
+	half := spread.Div(decimal.NewFromInt(2))
+	return r.Quote(
+		Quote{Price: fair.Sub(half).RoundFloor(execution.PricePlaces), Qty: qty},
+		Quote{Price: fair.Add(half).RoundCeil(execution.PricePlaces), Qty: qty},
+	)
+}
+
+// requote replaces the quote on one side. Called with r.quoting held.
+func (r *Runtime) requote(side execution.Side, q Quote, ticket *int64) error {
+	r.mu.Lock()
+	current := *ticket
+	r.mu.Unlock()
+	q.Qty = q.Qty.Truncate(execution.QtyPlaces)
+	if current != 0 {
+		o, _ := r.m.Order(current)
+		if o.State == execution.Open && q.Qty.IsPositive() &&
+			o.Price.Sub(q.Price).Abs().LessThanOrEqual(r.cfg.Tolerance) {
+			return nil
+		}
+		if err := r.cancel(current); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	*ticket = 0
+	r.mu.Unlock()
+	r.forget(current)
+	if !q.Qty.IsPositive() {
+		return nil
+	}
+	o, err := r.m.Place(side, q.Price, q.Qty)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	*ticket = o.Ticket
+	r.ours[o.Ticket] = true
+	r.mu.Unlock()
+	return nil
+}
+
+// cancel cancels an open quote. The Manager syncs as part of the cancel, so
+// fills up to the cancel still reach the position.
+func (r *Runtime) cancel(ticket int64) error {
+	if o, _ := r.m.Order(ticket); o.State != execution.Open {
+		return nil
+	}
+	return r.m.Cancel(ticket)
+}
+
+// forget drops a quote that is no longer open, so neither the Manager nor
+// the runtime keep every quote ever placed. Its fills have been delivered
+// by the cancel or sync that closed it.
+func (r *Runtime) forget(ticket int64) {
+	if ticket == 0 {
+		return
+	}
+	r.m.Forget(ticket)
+	if _, ok := r.m.Order(ticket); !ok {
+		r.mu.Lock()
+		delete(r.ours, ticket)
+		r.mu.Unlock()
+	}
+}
+
+// CancelQuotes cancels both quotes.
+func (r *Runtime) CancelQuotes() error {
+	r.quoting.Lock()
+	defer r.quoting.Unlock()
+	var first error
+	for _, ticket := range []*int64{&r.bid, &r.ask} {
+		r.mu.Lock()
+		current := *ticket
+		r.mu.Unlock()
+		if current == 0 {
+			continue
+		}
+		if err := r.cancel(current); err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		r.mu.Lock()
+		*ticket = 0
+		r.mu.Unlock()
+		r.forget(current)
+	}
+	return first
+}
+
+// Kill pulls the kill switch: no more quotes are placed and the resting
+// ones are cancelled. Kill blocks, retrying failed cancels with backoff,
+// until both quotes are confirmed gone; only then does Run return
+// ErrKilled. It is safe to call from any goroutine.
+func (r *Runtime) Kill(reason string) {
+	r.mu.Lock()
+	if r.killed {
+		r.mu.Unlock()
+		<-r.killc
+		return
+	}
+	r.killed = true
+	r.reason = reason
+	r.mu.Unlock()
+
+	wait := r.killRetry
+	for {
+		err := r.CancelQuotes()
+		if err == nil {
+			break
+		}
+		r.error(fmt.Errorf("kill switch: %v, retrying in %s", err, wait))
+		time.Sleep(wait)
+		if wait *= 2; wait > maxKillRetry {
+			wait = maxKillRetry
+		}
+	}
+	close(r.killc)
+	if r.OnKill != nil {
+		r.OnKill(reason)
+	}
+}
+
+// Killed reports whether the kill switch has been pulled.
+func (r *Runtime) Killed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.killed
+}
+
+// KillReason returns the reason passed to Kill.
+func (r *Runtime) KillReason() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reason
+}
+
+// Mid returns the mid price of book. ok is false if a side is empty.
+func Mid(book fyb.OrderBook) (mid decimal.Decimal, ok bool) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return decimal.Zero, false
+	}
+	return book.Bids[0].Price.Add(book.Asks[0].Price).Div(decimal.NewFromInt(2)), true
+}
@@ -0,0 +1,115 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	fyb "github.com/rakd/go-fyb"
+	"github.com/rakd/go-fyb/execution"
+	"github.com/rakd/go-fyb/execution/exectest"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func book(bid, ask string) fyb.OrderBook {
+	return fyb.OrderBook{
+		Bids: []fyb.PriceAmount{{Price: d(bid), Amount: d("1")}},
+		Asks: []fyb.PriceAmount{{Price: d(ask), Amount: d("1")}},
+	}
+}
+
+func TestMakerQuotes(t *testing.T) {
+	x, api := exectest.New(t)
+	var fills []execution.Fill
+	mk := &fillRecorder{Maker: &Maker{Spread: d("1"), Qty: d("0.5")}, fills: &fills}
+	m := execution.NewManager(api)
+	r := New(mk, m, nil, Config{MaxLong: d("0.5"), Tolerance: d("0.2")})
+	var errs []error
+	r.OnError = func(err error) { errs = append(errs, err) }
+
+	r.onBook(book("100", "102"))
+	bid, ask := r.Quotes()
+	require.Equal(t, "100.5", bid.Price.String())
+	require.Equal(t, "101.5", ask.Price.String())
+
+	// Within tolerance the quotes stay.
+	r.onBook(book("100.2", "102"))
+	require.Empty(t, x.Cancelled())
+
+	r.onBook(book("101", "103"))
+	require.Equal(t, []int64{1, 2}, x.Cancelled())
+	// Replaced quotes are forgotten.
+	_, known := m.Order(1)
+	require.False(t, known)
+	require.Len(t, r.ours, 2)
+	bid, ask = r.Quotes()
+	require.Equal(t, "101.5", bid.Price.String())
+	require.Equal(t, "102.5", ask.Price.String())
+
+	// The bid fills: the long limit is reached and only the ask is quoted.
+	x.Fill(bid.Ticket)
+	r.onTimer(time.Now())
+	require.Len(t, fills, 1)
+	require.Equal(t, "0.5", r.Position().String())
+	pnl, ok := r.PnL()
+	require.True(t, ok)
+	require.Equal(t, "0.25", pnl.String(), "bought 0.5 at 101.5, mid 102")
+
+	r.onBook(book("101", "103"))
+	bid, ask = r.Quotes()
+	require.Zero(t, bid.Ticket)
+	require.Equal(t, "102.5", ask.Price.String())
+	require.Len(t, x.Resting(), 1)
+	require.Empty(t, errs)
+}
+
+type fillRecorder struct {
+	*Maker
+	fills *[]execution.Fill
+}
+
+func (f *fillRecorder) OnFill(r *Runtime, fill execution.Fill) error {
+	*f.fills = append(*f.fills, fill)
+	return nil
+}
+
+func TestKillSwitch(t *testing.T) {
+	x, api := exectest.New(t)
+	r := New(&Maker{Spread: d("2"), Qty: d("1")}, execution.NewManager(api), nil, Config{MaxLoss: d("5")})
+	var reason string
+	r.OnKill = func(s string) { reason = s }
+
+	r.onBook(book("99", "101"))
+	bid, _ := r.Quotes()
+	x.Fill(bid.Ticket)
+	r.onTimer(time.Now())
+	require.Equal(t, "1", r.Position().String())
+
+	// Bought at 99, marked at 93: the loss limit of 5 is exceeded.
+	r.onBook(book("92", "94"))
+	require.True(t, r.Killed())
+	require.Equal(t, "loss limit exceeded: -6", reason)
+	require.Empty(t, x.Resting())
+	require.Equal(t, ErrKilled, r.Quote(Quote{Price: d("90"), Qty: d("1")}, Quote{}))
+	r.Kill("again")
+	require.Equal(t, "loss limit exceeded: -6", r.KillReason())
+}
+
+func TestKillRetriesCancels(t *testing.T) {
+	x, api := exectest.New(t)
+	r := New(&Maker{Spread: d("2"), Qty: d("1")}, execution.NewManager(api), nil, Config{})
+	r.killRetry = time.Millisecond
+	var errs []error
+	r.OnError = func(err error) { errs = append(errs, err) }
+
+	r.onBook(book("99", "101"))
+	require.Len(t, x.Resting(), 2)
+	x.FailCancels(3)
+	r.Kill("manual")
+	require.Empty(t, x.Resting(), "Kill returns only once the quotes are gone")
+	require.Len(t, errs, 2, "one error per attempt, two cancels failing in the first")
+}
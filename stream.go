@@ -0,0 +1,360 @@
+package fyb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// streamBody is the body of a streamed response. A read that waits longer
+// than idle for data cancels the request and fails with errTimeout. Close
+// closes the body and releases the request context.
+type streamBody struct {
+	body     io.ReadCloser
+	cancel   context.CancelFunc
+	idle     time.Duration
+	timer    *time.Timer
+	timedOut int32
+}
+
+func newStreamBody(cancel context.CancelFunc, idle time.Duration) *streamBody {
+	b := &streamBody{cancel: cancel, idle: idle}
+	b.timer = time.AfterFunc(idle, b.expire)
+	return b
+}
+
+func (b *streamBody) expire() {
+	atomic.StoreInt32(&b.timedOut, 1)
+	b.cancel()
+}
+
+func (b *streamBody) expired() bool {
+	return atomic.LoadInt32(&b.timedOut) == 1
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	b.timer.Reset(b.idle)
+	n, err := b.body.Read(p)
+	b.timer.Stop()
+	if err != nil && err != io.EOF && b.expired() {
+		err = errTimeout
+	}
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	b.timer.Stop()
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
+
+// stream performs call through the middleware chain with call.Stream set
+// and returns the response body unread, for incremental decoding. The
+// caller must close the body.
+func (c *Client) stream(method, resource string, payload map[string]string, authNeeded bool) (io.ReadCloser, error) {
+	call := newCall(method, resource, payload, authNeeded)
+	call.Stream = true
+	body, err := c.handler()(call)
+	if err != nil {
+		if call.StreamBody != nil {
+			call.StreamBody.Close()
+		}
+		return nil, err
+	}
+	if call.StreamBody == nil {
+		// A middleware answered with a body of its own.
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return call.StreamBody, nil
+}
+
+// smallStreamBody is the largest streamed response that is read before it
+// is returned, so that an error answered with status 200, such as a
+// rejected key, is classified like for other calls.
+const smallStreamBody = 4096
+
+// openStream performs a throttled request like doOnce but returns the
+// response body unread. It is not subject to the body size limit. The
+// headers must arrive within httpTimeout, and so must every chunk of the
+// body once the caller reads it.
+func (c *Client) openStream(call *Call) (io.ReadCloser, string, error) {
+	waitStart := time.Now()
+	<-c.throttle
+	c.metrics.ObserveThrottleWait(call.Endpoint, time.Since(waitStart))
+
+	ctx, cancel := context.WithCancel(call.Context)
+	// The timer runs until the headers arrive, then only during reads.
+	body := newStreamBody(cancel, c.httpTimeout)
+	timedOut := func(err error) error {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() || body.expired() && call.Context.Err() == nil {
+			return errTimeout
+		}
+		return err
+	}
+	start := time.Now()
+	var res apiResponse
+	var resp *http.Response
+	req, err := c.newRequest(ctx, call)
+	if err == nil {
+		if c.debug {
+			c.dumpRequest(req)
+		}
+		resp, err = c.httpClient.Do(req)
+	}
+	body.timer.Stop()
+	switch {
+	case err != nil:
+		res.err = timedOut(err)
+	case resp.StatusCode != 200:
+		res.status = resp.StatusCode
+		res.body, _ = ioutil.ReadAll(io.LimitReader(resp.Body, c.maxBody))
+		resp.Body.Close()
+		res.err = errors.New(resp.Status)
+	case resp.ContentLength >= 0 && resp.ContentLength <= smallStreamBody:
+		res.status = resp.StatusCode
+		body.body = resp.Body
+		res.body, err = ioutil.ReadAll(io.LimitReader(body, smallStreamBody))
+		resp.Body.Close()
+		if err != nil {
+			res.err = timedOut(err)
+		}
+		body.body = ioutil.NopCloser(bytes.NewReader(res.body))
+	default:
+		res.status = resp.StatusCode
+		body.body = resp.Body
+	}
+	latency := time.Since(start)
+	class := errorClass(res)
+	c.metrics.ObserveRequest(call.Endpoint, latency, class)
+	c.logCall(call, res, latency)
+	if res.err != nil {
+		cancel()
+		return nil, class, res.err
+	}
+	return body, class, nil
+}
+
+// expectDelim reads the next token from dec and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("unexpected JSON token %v, want %v", tok, delim)
+	}
+	return nil
+}
+
+// TradeIterator decodes a trade history response one trade at a time:
+//
+//	it, err := api.StreamTradeHistory(since)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		trade := it.Trade()
+//		...
+//	}
+//	return it.Err()
+type TradeIterator struct {
+	body    io.ReadCloser
+	dec     *json.Decoder
+	started bool
+	cur     Trade
+	err     error
+}
+
+// StreamTradeHistory is like GetTradeHistory but decodes trades from the
+// response as they are read, so long histories use constant memory and
+// can be abandoned early with Close.
+func (b *Fyb) StreamTradeHistory(tid int64) (*TradeIterator, error) {
+	body, err := b.client.stream("GET", fmt.Sprintf("trades.json?since=%d", tid), nil, false)
+	if err != nil {
+		return nil, err
+	}
+	return &TradeIterator{body: body, dec: json.NewDecoder(body)}, nil
+}
+
+// Next decodes the next trade. It returns false at the end of the response
+// or on error, and closes the response body.
+func (it *TradeIterator) Next() bool {
+	if it.err != nil || it.dec == nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if err := expectDelim(it.dec, '['); err != nil {
+			return it.fail(err)
+		}
+	}
+	if !it.dec.More() {
+		if err := expectDelim(it.dec, ']'); err != nil {
+			return it.fail(err)
+		}
+		it.Close()
+		return false
+	}
+	it.cur = Trade{}
+	if err := it.dec.Decode(&it.cur); err != nil {
+		return it.fail(err)
+	}
+	return true
+}
+
+// Trade returns the trade decoded by the last call to Next.
+func (it *TradeIterator) Trade() Trade {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *TradeIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the connection. It is safe to call
+// more than once.
+func (it *TradeIterator) Close() error {
+	it.dec = nil
+	return it.body.Close()
+}
+
+func (it *TradeIterator) fail(err error) bool {
+	it.err = err
+	it.Close()
+	return false
+}
+
+// OrderIterator decodes an order history response one order at a time.
+// It is used like TradeIterator.
+type OrderIterator struct {
+	body     io.ReadCloser
+	dec      *json.Decoder
+	started  bool
+	inOrders bool
+	code     int64
+	msg      string
+	cur      HistoryOrder
+	err      error
+}
+
+// StreamOrderHistory is like GetOrderHistory but decodes orders from the
+// response as they are read.
+func (b *Fyb) StreamOrderHistory(limit int64) (*OrderIterator, error) {
+	payload := map[string]string{"limit": fmt.Sprintf("%d", limit)}
+	body, err := b.client.stream("POST", "getorderhistory", payload, true)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderIterator{body: body, dec: json.NewDecoder(body)}, nil
+}
+
+// Next decodes the next order. It returns false at the end of the response
+// or on error, and closes the response body. An error reported by FYB in
+// the response is returned by Err.
+func (it *OrderIterator) Next() bool {
+	if it.err != nil || it.dec == nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if err := expectDelim(it.dec, '{'); err != nil {
+			return it.fail(err)
+		}
+	}
+	for {
+		if it.inOrders {
+			if it.dec.More() {
+				it.cur = HistoryOrder{}
+				if err := it.dec.Decode(&it.cur); err != nil {
+					return it.fail(err)
+				}
+				return true
+			}
+			if err := expectDelim(it.dec, ']'); err != nil {
+				return it.fail(err)
+			}
+			it.inOrders = false
+		}
+		if !it.dec.More() {
+			if err := expectDelim(it.dec, '}'); err != nil {
+				return it.fail(err)
+			}
+			if it.code != 0 {
+				return it.fail(fmt.Errorf("FYB error %d: %s", it.code, it.msg))
+			}
+			it.Close()
+			return false
+		}
+		if err := it.field(); err != nil {
+			return it.fail(err)
+		}
+	}
+}
+
+// field reads the next key of the top-level object and its value, unless
+// it is the orders array, which Next decodes element by element.
+func (it *OrderIterator) field() error {
+	tok, err := it.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case "orders":
+		if err := expectDelim(it.dec, '['); err != nil {
+			return err
+		}
+		it.inOrders = true
+	case "error":
+		// A number in normal responses, a message for a rejected key.
+		var v interface{}
+		if err := it.dec.Decode(&v); err != nil {
+			return err
+		}
+		switch v := v.(type) {
+		case string:
+			return errors.New(v)
+		case float64:
+			it.code = int64(v)
+		}
+	case "msg":
+		return it.dec.Decode(&it.msg)
+	default:
+		var skip json.RawMessage
+		return it.dec.Decode(&skip)
+	}
+	return nil
+}
+
+// Order returns the order decoded by the last call to Next.
+func (it *OrderIterator) Order() HistoryOrder {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the connection. It is safe to call
+// more than once.
+func (it *OrderIterator) Close() error {
+	it.dec = nil
+	return it.body.Close()
+}
+
+func (it *OrderIterator) fail(err error) bool {
+	it.err = err
+	it.Close()
+	return false
+}
@@ -0,0 +1,139 @@
+package fyb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTradeHistory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "5", r.URL.Query().Get("since"))
+		fmt.Fprint(w, "[")
+		for i := 0; i < 1000; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"amount":"0.01","date":1387975210,"price":"1011.00","tid":%d}`, 6+i)
+		}
+		fmt.Fprint(w, "]")
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "", "")
+	api.SetMaxBodySize(1024)
+	it, err := api.StreamTradeHistory(5)
+	require.NoError(t, err)
+	var n int
+	for it.Next() {
+		require.Equal(t, int64(6+n), it.Trade().TID)
+		n++
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, 1000, n)
+
+	it, err = api.StreamTradeHistory(5)
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.NoError(t, it.Close())
+	require.False(t, it.Next())
+	require.NoError(t, it.Err())
+}
+
+func TestStreamOrderHistory(t *testing.T) {
+	body := `{"error":0,"orders":[{"date_created":1387971414,"date_executed":1387971414,"price":"S$3.00","qty":"2.00000000BTC","status":"A","ticket":11,"type":"B"},{"date_created":1387971314,"date_executed":1387971414,"price":"S$3.00","qty":"2.00000000BTC","status":"F","ticket":6,"type":"S"}]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "5", r.FormValue("limit"))
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "key", "secret")
+	it, err := api.StreamOrderHistory(5)
+	require.NoError(t, err)
+	var tickets []int64
+	for it.Next() {
+		tickets = append(tickets, it.Order().Ticket)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, []int64{11, 6}, tickets)
+
+	body = `{"error":"Key/Sign not valid"}`
+	it, err = api.StreamOrderHistory(5)
+	require.NoError(t, err)
+	require.False(t, it.Next())
+	require.EqualError(t, it.Err(), "Key/Sign not valid")
+}
+
+func TestStreamTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "1" {
+			// No headers.
+			<-release
+			return
+		}
+		// Headers and one trade, then nothing.
+		fmt.Fprint(w, `[{"amount":"0.01","date":1387975210,"price":"1011.00","tid":6},`)
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	api := NewWithCustomTimeout(ts.URL, "", "", 200*time.Millisecond)
+	_, err := api.StreamTradeHistory(1)
+	require.Equal(t, errTimeout, err)
+
+	it, err := api.StreamTradeHistory(5)
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	require.False(t, it.Next())
+	require.Equal(t, errTimeout, it.Err())
+}
+
+func TestStreamMiddleware(t *testing.T) {
+	body := `[{"amount":"0.01","date":1387975210,"price":"1011.00","tid":6}]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/getorderhistory" {
+			fmt.Fprint(w, `{"error":"No Key with that name found"}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	api := New(ts.URL, "key", "secret")
+	var endpoints []string
+	api.Use(func(next Handler) Handler {
+		return func(call *Call) ([]byte, error) {
+			endpoints = append(endpoints, call.Endpoint)
+			return next(call)
+		}
+	})
+	var hooked string
+	api.Use(ResponseHook(func(call *Call, b []byte) { hooked = string(b) }))
+	m := NewPrometheusMetrics()
+	api.SetMetrics(m)
+
+	it, err := api.StreamTradeHistory(5)
+	require.NoError(t, err)
+	for it.Next() {
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, body, hooked, "the hook gets the body once it is closed")
+
+	it2, err := api.StreamOrderHistory(5)
+	require.NoError(t, err)
+	require.False(t, it2.Next())
+	require.EqualError(t, it2.Err(), "No Key with that name found")
+	require.Equal(t, []string{"trades.json", "getorderhistory"}, endpoints)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Contains(t, rec.Body.String(), `fyb_request_errors_total{endpoint="getorderhistory",class="auth"} 1`)
+}
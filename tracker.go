@@ -0,0 +1,220 @@
+package fyb
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errSlowSubscriber = errors.New("tracker subscriber too slow, trades dropped")
+
+// Tracker polls the order book and trade history on one schedule and fans
+// the updates out to any number of subscribers, so that many consumers cost
+// a single stream of API calls.
+type Tracker struct {
+	api      *Fyb
+	interval time.Duration
+
+	// OnError, if set, is called with errors from polling. Set it before Start.
+	OnError func(err error)
+
+	mu        sync.Mutex
+	book      OrderBook
+	hasBook   bool
+	lastTID   int64
+	bookSubs  map[chan OrderBook]struct{}
+	tradeSubs map[chan Trades]struct{}
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewTracker returns a Tracker polling api every interval. Trades after
+// sinceTID are delivered; if sinceTID is 0 the first batch of trades only
+// sets the starting point and is not delivered.
+func NewTracker(api *Fyb, interval time.Duration, sinceTID int64) *Tracker {
+	return &Tracker{
+		api:       api,
+		interval:  interval,
+		lastTID:   sinceTID,
+		bookSubs:  map[chan OrderBook]struct{}{},
+		tradeSubs: map[chan Trades]struct{}{},
+	}
+}
+
+// Start begins polling in the background.
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return
+	}
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.loop(t.stop, t.done)
+}
+
+// Stop stops polling and waits for the current poll to finish.
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	stop, done := t.stop, t.done
+	t.stop, t.done = nil, nil
+	t.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (t *Tracker) loop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		t.Poll()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the order book and new trades once and notifies
+// subscribers. It is called by the background loop but can also be used
+// directly to drive the Tracker manually.
+func (t *Tracker) Poll() {
+	book, err := t.api.GetOrderBook()
+	if err != nil {
+		t.error(err)
+	} else {
+		t.updateBook(book)
+	}
+
+	t.mu.Lock()
+	since := t.lastTID
+	t.mu.Unlock()
+	trades, err := t.api.GetTradeHistory(since)
+	if err != nil {
+		t.error(err)
+		return
+	}
+	t.updateTrades(since, trades)
+}
+
+func (t *Tracker) error(err error) {
+	if t.OnError != nil {
+		t.OnError(err)
+	}
+}
+
+func (t *Tracker) updateBook(book OrderBook) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hasBook && bookEqual(t.book, book) {
+		return
+	}
+	t.book, t.hasBook = book, true
+	for ch := range t.bookSubs {
+		// Keep only the latest book for slow subscribers.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- book
+	}
+}
+
+func (t *Tracker) updateTrades(since int64, trades Trades) {
+	t.mu.Lock()
+	sorted := append(Trades(nil), trades...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TID < sorted[j].TID })
+	var fresh Trades
+	for _, trade := range sorted {
+		if trade.TID > t.lastTID {
+			fresh = append(fresh, trade)
+			t.lastTID = trade.TID
+		}
+	}
+	if len(fresh) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	dropped := false
+	if since != 0 {
+		for ch := range t.tradeSubs {
+			select {
+			case ch <- fresh:
+			default:
+				dropped = true
+			}
+		}
+	}
+	t.mu.Unlock()
+	if dropped {
+		t.error(errSlowSubscriber)
+	}
+}
+
+// Book returns the latest order book and whether one has been fetched yet.
+func (t *Tracker) Book() (OrderBook, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.book, t.hasBook
+}
+
+// LastTID returns the ID of the newest trade seen.
+func (t *Tracker) LastTID() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastTID
+}
+
+// SubscribeBook returns a channel receiving the order book whenever it
+// changes, starting with the current one if known. A slow reader only sees
+// the latest book. Call the returned function to unsubscribe.
+func (t *Tracker) SubscribeBook() (<-chan OrderBook, func()) {
+	ch := make(chan OrderBook, 1)
+	t.mu.Lock()
+	t.bookSubs[ch] = struct{}{}
+	if t.hasBook {
+		ch <- t.book
+	}
+	t.mu.Unlock()
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.bookSubs, ch)
+		t.mu.Unlock()
+	}
+}
+
+// SubscribeTrades returns a channel receiving batches of new trades, oldest
+// first. Batches are dropped if the reader falls more than 64 batches
+// behind. Call the returned function to unsubscribe.
+func (t *Tracker) SubscribeTrades() (<-chan Trades, func()) {
+	ch := make(chan Trades, 64)
+	t.mu.Lock()
+	t.tradeSubs[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.tradeSubs, ch)
+		t.mu.Unlock()
+	}
+}
+
+func bookEqual(a, b OrderBook) bool {
+	return levelsEqual(a.Asks, b.Asks) && levelsEqual(a.Bids, b.Bids)
+}
+
+func levelsEqual(a, b []PriceAmount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Price.Equal(b[i].Price) || !a[i].Amount.Equal(b[i].Amount) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package fyb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	var mu sync.Mutex
+	book := `{"asks":[[1012.48,0.5]],"bids":[[1005.17,0.3]]}`
+	trades := `[{"amount":"0.01","date":1387975210,"price":"1011.00","tid":100}]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/orderbook.json":
+			w.Write([]byte(book))
+		case "/trades.json":
+			w.Write([]byte(trades))
+		}
+	}))
+	defer ts.Close()
+
+	tracker := NewTracker(New(ts.URL, "", ""), 0, 0)
+	bookCh, unsubBook := tracker.SubscribeBook()
+	defer unsubBook()
+	tradeCh, unsubTrades := tracker.SubscribeTrades()
+	defer unsubTrades()
+
+	tracker.Poll()
+	got := <-bookCh
+	require.Equal(t, "1012.48", got.Asks[0].Price.String())
+	require.Equal(t, int64(100), tracker.LastTID())
+	require.Len(t, tradeCh, 0, "first batch only sets the starting point")
+
+	mu.Lock()
+	trades = `[{"amount":"0.2","date":1387975220,"price":"1012.00","tid":102},{"amount":"0.1","date":1387975215,"price":"1011.50","tid":101}]`
+	mu.Unlock()
+	tracker.Poll()
+	require.Len(t, bookCh, 0, "unchanged book is not resent")
+	batch := <-tradeCh
+	require.Len(t, batch, 2)
+	require.Equal(t, int64(101), batch[0].TID)
+	require.Equal(t, int64(102), tracker.LastTID())
+}
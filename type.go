@@ -38,40 +38,42 @@ type PriceAmount struct {
 	Amount decimal.Decimal `json:"amount"`
 }
 
-// NewPriceAmountFromInterface ..
+// NewPriceAmountFromInterface parses an order book level, a [price, amount]
+// array as decoded from JSON.
 func NewPriceAmountFromInterface(i interface{}) (PriceAmount, error) {
 	var item PriceAmount
-	s := fmt.Sprintf("%v", i)
-
-	if len(s) < 5 {
-		return item, fmt.Errorf("wrong interface for PriceAmount")
-	}
-
-	if !strings.HasPrefix(s, "[") {
-		return item, fmt.Errorf("wrong interface for PriceAmount")
-	}
-	if !strings.HasSuffix(s, "]") {
+	level, ok := i.([]interface{})
+	if !ok || len(level) != 2 {
 		return item, fmt.Errorf("wrong interface for PriceAmount")
 	}
-
-	s = s[1 : len(s)-2]
-	decimals := strings.Split(s, " ")
-	if len(decimals) != 2 {
-		return item, fmt.Errorf("wrong interface for PriceAmount")
-	}
-	price, err := decimal.NewFromString(decimals[0])
+	price, err := decimal.NewFromString(fmt.Sprint(level[0]))
 	if err != nil {
 		return item, err
 	}
 	item.Price = price
-	amount, err := decimal.NewFromString(decimals[1])
+	amount, err := decimal.NewFromString(fmt.Sprint(level[1]))
 	if err != nil {
 		return item, err
 	}
 	item.Amount = amount
 
 	return item, nil
+}
 
+// ParseAmount parses FYB price and quantity strings such as "5.00",
+// "S$3.00", "2.00000000BTC" or "kr10.00", ignoring currency prefixes and
+// suffixes.
+func ParseAmount(s string) (decimal.Decimal, error) {
+	start := strings.IndexFunc(s, func(r rune) bool {
+		return (r >= '0' && r <= '9') || r == '-' || r == '.'
+	})
+	end := strings.LastIndexFunc(s, func(r rune) bool {
+		return r >= '0' && r <= '9'
+	})
+	if start < 0 || end < start {
+		return decimal.Zero, fmt.Errorf("wrong amount %q", s)
+	}
+	return decimal.NewFromString(s[start : end+1])
 }
 
 //
@@ -98,7 +100,17 @@ type AccountInfoResponse struct {
 	Email      string          `json:"email"`
 	Error      int64           `json:"error"`
 	SgdBal     decimal.Decimal `json:"sgdBal"`
-	Msg        string          `json:"msg"` // for error handling
+	SekBal     decimal.Decimal `json:"sekBal"` // fybse.se
+	Msg        string          `json:"msg"`    // for error handling
+}
+
+// FiatBal returns the fiat balance for the market's currency: SekBal for
+// "SEK", SgdBal otherwise. See Fyb.Currency.
+func (r AccountInfoResponse) FiatBal(currency string) decimal.Decimal {
+	if currency == "SEK" {
+		return r.SekBal
+	}
+	return r.SgdBal
 }
 
 // PendingOrderResponse ...
@@ -204,17 +216,20 @@ type PendingOrderResponse struct {
   ]
 }*/
 type OrderHistoryResponse struct {
-	Error  int64  `json:"error"`
-	Msg    string `json:"msg"` // for error handling
-	Orders []struct {
-		DateCreated  int64  `json:"date_created"`
-		DateExecuted int64  `json:"date_executed"`
-		Price        string `json:"price"`
-		Qty          string `json:"qty"`
-		//Status int64 `json:"status"` // it's wrong, erro occured
-		//Type   int64 `json:"type"`// it's wrong, erro occured
-		Ticket int64 `json:"ticket"`
-	} `json:"orders"`
+	Error  int64          `json:"error"`
+	Msg    string         `json:"msg"` // for error handling
+	Orders []HistoryOrder `json:"orders"`
+}
+
+// HistoryOrder is an order in the order history.
+type HistoryOrder struct {
+	DateCreated  int64  `json:"date_created"`
+	DateExecuted int64  `json:"date_executed"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	Status       string `json:"status"` // e.g. "A" or "F"
+	Type         string `json:"type"`   // "B" or "S"
+	Ticket       int64  `json:"ticket"`
 }
 
 // PlaceOrderResponse ..